@@ -0,0 +1,136 @@
+package bin
+
+import "fmt"
+
+/*
+===============================================================================
+    Reader
+===============================================================================
+*/
+
+// Align discards bytes up to the next multiple of `n`, relative to the align
+// base (see `SetAlignBase`). Nothing is discarded if the reader is already
+// aligned.
+func (b *Reader) Align(n int64) error {
+	if b.source == nil {
+		return b.newError("Align", ErrNilSource)
+	}
+	if n < 1 {
+		return b.newError("Align", fmt.Errorf("%w: %d", errBadAlignment, n))
+	}
+	pad := b.padding(n)
+	if pad == 0 {
+		return nil
+	}
+	start := b.pos
+	if err := b.discard(pad); err != nil {
+		return &Error{Op: "Align", Offset: start, Requested: int(pad), Got: int(b.pos - start), Err: err}
+	}
+	return nil
+}
+
+// AlignZero is as `Align`, but additionally checks that the discarded padding
+// consists solely of null-bytes. If it does not, an error wrapping
+// `ErrNonZeroPadding` is returned whose `Offset` is that of the first
+// non-zero byte; the padding is consumed regardless.
+func (b *Reader) AlignZero(n int64) error {
+	if b.source == nil {
+		return b.newError("AlignZero", ErrNilSource)
+	}
+	if n < 1 {
+		return b.newError("AlignZero", fmt.Errorf("%w: %d", errBadAlignment, n))
+	}
+	pad := b.padding(n)
+	var err error
+	for pad > 0 {
+		b.i = len(b._1kb)
+		if pad < int64(b.i) {
+			b.i = int(pad)
+		}
+		start := b.pos
+		if e := b.readFull("AlignZero", b._1kb[:b.i]); e != nil {
+			return e
+		}
+		for i := 0; i < b.i && err == nil; i++ {
+			if c := b._1kb[i]; c != 0 {
+				err = &Error{Op: "AlignZero", Offset: start + int64(i), Err: fmt.Errorf("%w: found %#02x", ErrNonZeroPadding, c)}
+			}
+		}
+		pad -= int64(b.i)
+	}
+	return err
+}
+
+/*
+===============================================================================
+    Writer
+===============================================================================
+*/
+
+// Align writes null-bytes up to the next multiple of `n`, relative to the
+// align base (see `SetAlignBase`). Nothing is written if the writer is
+// already aligned.
+func (b *Writer) Align(n int64) error {
+	return b.alignPad("Align", n, 0)
+}
+
+// AlignPad is as `Align`, but pads with `pad` rather than null-bytes.
+func (b *Writer) AlignPad(n int64, pad byte) error {
+	return b.alignPad("AlignPad", n, pad)
+}
+
+// alignPad is the implementation of `Align` and `AlignPad`.
+func (b *Writer) alignPad(op string, n int64, c byte) error {
+	if b.dest == nil {
+		return b.newError(op, ErrNilSource)
+	}
+	if n < 1 {
+		return b.newError(op, fmt.Errorf("%w: %d", errBadAlignment, n))
+	}
+	pad := b.padding(n)
+	if pad == 0 {
+		return nil
+	}
+	start := b.pos
+	if err := b.pad(pad, c); err != nil {
+		return &Error{Op: op, Offset: start, Requested: int(pad), Got: int(b.pos - start), Err: err}
+	}
+	return nil
+}
+
+/*
+===============================================================================
+    binaryBase
+===============================================================================
+*/
+
+// SetAlignBase sets the position, as returned by `GetPosition`, relative to
+// which `Align` computes boundaries. This is useful when fields are aligned
+// relative to the start of a container rather than to the start of the
+// stream. The default is zero, and it is reset by `Reset`.
+//
+// Note that sub-readers (see `Reader.SubReader`) are already positioned
+// relative to their container.
+func (b *binaryBase) SetAlignBase(pos int64) {
+	b.alignBase = pos
+}
+
+// GetAlignBase returns the position relative to which `Align` computes
+// boundaries.
+func (b *binaryBase) GetAlignBase() int64 {
+	return b.alignBase
+}
+
+// padding returns the number of bytes from the current position to the next
+// multiple of `n` relative to the align base.
+func (b *binaryBase) padding(n int64) int64 {
+	m := (b.pos - b.alignBase) % n
+	if m < 0 {
+		// the align base lies ahead of the current position
+		m += n
+	}
+	if m == 0 {
+		return 0
+	}
+	return n - m
+}
@@ -1,11 +1,18 @@
 // Package bin provides utility interfaces for working with binary data streams
+//
+// Failures are reported as an `*Error`, which describes the operation and
+// offset at which they occurred and wraps the cause. Earlier versions
+// returned the cause itself, so comparisons such as `err == io.EOF` or
+// `err == io.ErrUnexpectedEOF` no longer match, and should be replaced with
+// `errors.Is(err, io.EOF)` and the like. Only `Reader.Read`, as an
+// `io.Reader`, returns the errors of its source unchanged.
 package bin
 
 import (
 	"bytes"
 	"encoding/binary"
-	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"math"
 )
@@ -19,23 +26,47 @@ import (
 // Reader provides methods for reading various data types from an `io.Reader`.
 type Reader struct {
 	binaryBase
-	source     io.Reader
-	peekBuffer []byte // this is initially set to 64 bytes
-	nPeeked    int
-	peekPos    int
+	source    io.Reader
+	buf       []byte           // read-ahead buffer; `buf[off:]` is unconsumed
+	off       int              // offset of the next unconsumed byte in `buf`
+	maxBuf    int              // zero implies `DefaultReadAheadLimit`
+	srcBytes  []byte           // the slice wrapped by `source`, if any
+	mark      int              // offset of the mark in `buf`, if `marked`
+	markPos   int64            // reader position of the mark
+	marked    bool             // whether consumed bytes are being retained
+	markLost  bool             // whether the mark exceeded the retention limit
+	maxRetain int              // zero implies `DefaultRetentionLimit`
+	base      int64            // absolute offset of position zero
+	container *containerSource // non-nil for sub-readers
+}
+
+// containerSource limits reads from a parent `Reader` to a fixed number of
+// bytes, for use as the source of a sub-reader.
+type containerSource struct {
+	parent    *Reader
+	limit     int64
+	remaining int64
 }
 
 // Writer provides methods for reading various data types to an `io.Writer`.
 type Writer struct {
 	binaryBase
-	dest io.Writer
+	dest     io.Writer
+	buf      []byte // non-nil for buffered writers (see `NewBufferedWriter`)
+	held     []byte // writes held back until reservations are patched
+	heldBase int64  // position of the first held byte
+	open     int    // number of unpatched reservations in `held`
+	epoch    int    // incremented by `Reset` to invalidate reservations
 }
 
 // binaryBase contains a set of methods and variables common to sibling
 // interfaces.
 type binaryBase struct {
-	pos int64
-	bo  binary.ByteOrder
+	pos          int64
+	bo           binary.ByteOrder
+	maxVarintLen int         // zero implies `binary.MaxVarintLen64`
+	alignBase    int64       // position relative to which `Align` computes boundaries
+	hashes       []hash.Hash // active hashes (see `BeginHash`)
 	tmpBuffers
 }
 
@@ -52,6 +83,22 @@ type tmpBuffers struct {
 	err     error
 }
 
+// DefaultReadAheadLimit is the maximum number of bytes a `Reader` buffers
+// ahead of its position unless changed with `Reader.SetReadAheadLimit`.
+const DefaultReadAheadLimit = 64 << 10
+
+// DefaultRetentionLimit is the maximum number of bytes a `Reader` retains
+// after `Reader.Mark` unless changed with `Reader.SetRetentionLimit`.
+const DefaultRetentionLimit = 1 << 20
+
+// DefaultBufferSize is the size of the buffer of a writer created by
+// `NewBufferedWriter` with a non-positive size.
+const DefaultBufferSize = 4096
+
+// allocChunk is the most memory allocated up front for a length read from
+// the source; beyond it, memory is allocated only as the bytes arrive.
+const allocChunk = 64 << 10
+
 /*
 ===============================================================================
     Reader
@@ -60,16 +107,34 @@ type tmpBuffers struct {
 
 // ReadByte reads one byte into `dst`.
 func (b *Reader) ReadByte(dst *byte) error {
-	if b.err = b.ReadBytes(b._1kb[:1]); b.err != nil {
-		return b.err
+	if b.source == nil {
+		return b.newError("ReadByte", ErrNilSource)
+	}
+	if err := b.readFull("ReadByte", b._1kb[:1]); err != nil {
+		return err
 	}
 	*dst = b._1kb[0]
 	return nil
 }
 
-// Read satisfies the Liskov Subsitution Principle of its base `io.Reader`
+// Read satisfies the Liskov Subsitution Principle of its base `io.Reader`.
+// Bytes buffered by `Peek` are returned before any are read from the source.
 func (b *Reader) Read(p []byte) (n int, err error) {
+	if len(p) > 0 && b.buffered() == 0 && b.retaining(len(p)) {
+		// read through the buffer so that the bytes are retained
+		if err = b.peekMore(len(p)); err != nil {
+			return 0, err
+		}
+	}
+	if b.buffered() > 0 {
+		n = copy(p, b.buf[b.off:])
+		b.consume(n)
+		return n, nil
+	}
 	n, err = b.source.Read(p)
+	if len(b.hashes) > 0 && n > 0 && n <= len(p) {
+		b.tap(p[:n])
+	}
 	b.pos += int64(n)
 	return
 }
@@ -77,60 +142,98 @@ func (b *Reader) Read(p []byte) (n int, err error) {
 // ReadBytes attempts to read `len(dst)` bytes into `dst`.
 // Multiple calls will be made to `source.Read` in the case of a partial read.
 //
-// If unable to completely read into `dst`, `io.ErrUnexpectedEOF` will be returned.
+// If unable to completely read into `dst`, an `*Error` wrapping
+// `io.ErrUnexpectedEOF` (or `io.EOF`, if no bytes were read) will be returned.
 func (b *Reader) ReadBytes(dst []byte) error {
 	if b.source == nil {
-		return fmt.Errorf("ReadBytes([%d]byte): reader is nil", len(dst))
+		return b.newError("ReadBytes", ErrNilSource)
+	}
+	return b.readFull("ReadBytes", dst)
+}
+
+// readFull reads exactly `len(dst)` bytes into `dst`, describing any failure as
+// an `*Error` for the operation `op`.
+func (b *Reader) readFull(op string, dst []byte) error {
+	start := b.pos
+	if err := b.read(dst); err != nil {
+		return &Error{Op: op, Offset: start, Requested: len(dst), Got: int(b.pos - start), Err: err}
+	}
+	return nil
+}
+
+// readAlloc reads `n` bytes into `dst`, which is reallocated if its capacity
+// is insufficient, and describes any failure as an `*Error` for the operation
+// `op`. A reallocated `dst` is grown in steps as bytes are read, so that a
+// hostile length read from the source cannot allocate more than twice the
+// bytes actually available, plus `allocChunk`.
+func (b *Reader) readAlloc(op string, dst []byte, n int) ([]byte, error) {
+	if n <= cap(dst) {
+		dst = dst[:n]
+		return dst, b.readFull(op, dst)
+	}
+	start := b.pos
+	dst = dst[:0]
+	for len(dst) < n {
+		size := 2 * cap(dst)
+		if size < allocChunk {
+			size = allocChunk
+		}
+		if size > n {
+			size = n
+		}
+		grown := make([]byte, size)
+		copy(grown, dst)
+		if err := b.read(grown[len(dst):]); err != nil {
+			return nil, &Error{Op: op, Offset: start, Requested: n, Got: int(b.pos - start), Err: err}
+		}
+		dst = grown
 	}
+	return dst, nil
+}
+
+// read is the implementation of `ReadBytes`, returning errors from the source
+// as-is. The source must not be nil.
+func (b *Reader) read(dst []byte) error {
 	// shortcut if `dst` has a length of zero
 	if len(dst) == 0 {
 		return nil
 	}
-	if b.numUnusedPeekedBytes() > 0 {
-		b.i = len(dst)
-		// we have peeked some bytes.
-		// therefore, they should be used before remaining bytes in reader.
-		if b.numUnusedPeekedBytes() >= b.i {
-			// unused bytes in peek buffer exceeed the destination length,
-			// so use up what we can
-			copy(dst, b.peekBuffer[b.peekPos:b.peekPos+b.i])
-			b.peekPos += b.i
-			b.err = nil
-		} else {
-			// more bytes are requested than available in peek buffer
-			// fulfill partially from peek buffer, then `io.ReadFull` the rest
-			copy(dst, b.peekBuffer[b.peekPos:b.nPeeked])
-
-			b.i, b.err = io.ReadFull(b.source, dst[(b.nPeeked-b.peekPos):])
-
-			// also advance reader position by those bytes we used
-			b.i += (b.nPeeked - b.peekPos)
-			// b.nPeeked = 0
-			// b.peekPos = 0
-			// TODO: Is above better?
-			b.peekPos = b.nPeeked
-
+	if b.retaining(len(dst)) {
+		// read through the buffer so that the bytes are retained
+		err := b.fill(len(dst))
+		b.consume(copy(dst, b.buf[b.off:]))
+		return err
+	}
+	n := 0
+	if b.buffered() > 0 {
+		// bytes already read ahead come first
+		n = copy(dst, b.buf[b.off:])
+		b.consume(n)
+		if n == len(dst) {
+			return nil
 		}
-	} else {
-		// here `io.ReadFull` is used to ensure all requested bytes are read
-		// via repeated `Read` calls
-		b.i, b.err = io.ReadFull(b.source, dst)
 	}
-
-	b.pos += int64(b.i)
-	return b.err
+	// here `io.ReadFull` is used to ensure all requested bytes are read
+	// via repeated `Read` calls
+	m, err := io.ReadFull(b.source, dst[n:])
+	b.tap(dst[n : n+m])
+	b.pos += int64(m)
+	if err == io.EOF && n > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	return b.bounded(err)
 }
 
 // ReadUint16 reads an unsigned 16-bit integer into `dst` according to the current byte order.
 func (b *Reader) ReadUint16(dst *uint16) error {
 	if b.source == nil {
-		return errors.New("ReadUint16(): reader is nil")
+		return b.newError("ReadUint16", ErrNilSource)
 	}
 	if b.bo == nil {
-		return errors.New("ReadUint16(): ByteOrder is not set")
+		return b.newError("ReadUint16", ErrNilByteOrder)
 	}
-	if b.err = b.ReadBytes(b._1kb[:2]); b.err != nil {
-		return b.err
+	if err := b.readFull("ReadUint16", b._1kb[:2]); err != nil {
+		return err
 	}
 	*dst = b.bo.Uint16(b._1kb[:2])
 	return nil
@@ -139,13 +242,13 @@ func (b *Reader) ReadUint16(dst *uint16) error {
 // ReadUint32 reads an unsigned 32-bit integer into `dst` according to the current byte order.
 func (b *Reader) ReadUint32(dst *uint32) error {
 	if b.source == nil {
-		return errors.New("ReadUint32(): reader is nil")
+		return b.newError("ReadUint32", ErrNilSource)
 	}
 	if b.bo == nil {
-		return errors.New("ReadUint32(): ByteOrder is not set")
+		return b.newError("ReadUint32", ErrNilByteOrder)
 	}
-	if b.err = b.ReadBytes(b._1kb[:4]); b.err != nil {
-		return b.err
+	if err := b.readFull("ReadUint32", b._1kb[:4]); err != nil {
+		return err
 	}
 	*dst = b.bo.Uint32(b._1kb[:4])
 	return nil
@@ -154,29 +257,88 @@ func (b *Reader) ReadUint32(dst *uint32) error {
 // ReadUint64 reads an unsigned 64-bit integer into `dst` according to the current byte order.
 func (b *Reader) ReadUint64(dst *uint64) error {
 	if b.source == nil {
-		return errors.New("ReadUint64(): reader is nil")
+		return b.newError("ReadUint64", ErrNilSource)
 	}
 	if b.bo == nil {
-		return errors.New("ReadUint64(): ByteOrder is not set")
+		return b.newError("ReadUint64", ErrNilByteOrder)
 	}
-	if b.err = b.ReadBytes(b._1kb[:8]); b.err != nil {
-		return b.err
+	if err := b.readFull("ReadUint64", b._1kb[:8]); err != nil {
+		return err
 	}
 	*dst = b.bo.Uint64(b._1kb[:8])
 	return nil
 }
 
+// ReadInt8 reads a signed 8-bit integer into `dst`.
+//
+// As a single byte has no endianness, the current byte order is not consulted.
+func (b *Reader) ReadInt8(dst *int8) error {
+	if b.source == nil {
+		return b.newError("ReadInt8", ErrNilSource)
+	}
+	if err := b.readFull("ReadInt8", b._1kb[:1]); err != nil {
+		return err
+	}
+	*dst = int8(b._1kb[0])
+	return nil
+}
+
+// ReadInt16 reads a signed 16-bit integer into `dst` according to the current byte order.
+func (b *Reader) ReadInt16(dst *int16) error {
+	if b.source == nil {
+		return b.newError("ReadInt16", ErrNilSource)
+	}
+	if b.bo == nil {
+		return b.newError("ReadInt16", ErrNilByteOrder)
+	}
+	if err := b.readFull("ReadInt16", b._1kb[:2]); err != nil {
+		return err
+	}
+	*dst = int16(b.bo.Uint16(b._1kb[:2]))
+	return nil
+}
+
+// ReadInt32 reads a signed 32-bit integer into `dst` according to the current byte order.
+func (b *Reader) ReadInt32(dst *int32) error {
+	if b.source == nil {
+		return b.newError("ReadInt32", ErrNilSource)
+	}
+	if b.bo == nil {
+		return b.newError("ReadInt32", ErrNilByteOrder)
+	}
+	if err := b.readFull("ReadInt32", b._1kb[:4]); err != nil {
+		return err
+	}
+	*dst = int32(b.bo.Uint32(b._1kb[:4]))
+	return nil
+}
+
+// ReadInt64 reads a signed 64-bit integer into `dst` according to the current byte order.
+func (b *Reader) ReadInt64(dst *int64) error {
+	if b.source == nil {
+		return b.newError("ReadInt64", ErrNilSource)
+	}
+	if b.bo == nil {
+		return b.newError("ReadInt64", ErrNilByteOrder)
+	}
+	if err := b.readFull("ReadInt64", b._1kb[:8]); err != nil {
+		return err
+	}
+	*dst = int64(b.bo.Uint64(b._1kb[:8]))
+	return nil
+}
+
 // ReadFloat32 reads a 32-bit IEEE 754 floating-point integer into `dst`
 // according to the current byte order.
 func (b *Reader) ReadFloat32(dst *float32) error {
 	if b.source == nil {
-		return errors.New("ReadFloat32(): reader is nil")
+		return b.newError("ReadFloat32", ErrNilSource)
 	}
 	if b.bo == nil {
-		return errors.New("ReadFloat32(): ByteOrder is not set")
+		return b.newError("ReadFloat32", ErrNilByteOrder)
 	}
-	if b.err = b.ReadBytes(b._1kb[:4]); b.err != nil {
-		return b.err
+	if err := b.readFull("ReadFloat32", b._1kb[:4]); err != nil {
+		return err
 	}
 	*dst = math.Float32frombits(b.bo.Uint32(b._1kb[:4]))
 	return nil
@@ -186,101 +348,473 @@ func (b *Reader) ReadFloat32(dst *float32) error {
 // according to the current byte order.
 func (b *Reader) ReadFloat64(dst *float64) error {
 	if b.source == nil {
-		return errors.New("ReadFloat64(): reader is nil")
+		return b.newError("ReadFloat64", ErrNilSource)
 	}
 	if b.bo == nil {
-		return errors.New("ReadFloat64(): ByteOrder is not set")
+		return b.newError("ReadFloat64", ErrNilByteOrder)
 	}
-	if b.err = b.ReadBytes(b._1kb[:8]); b.err != nil {
-		return b.err
+	if err := b.readFull("ReadFloat64", b._1kb[:8]); err != nil {
+		return err
 	}
 	*dst = math.Float64frombits(b.bo.Uint64(b._1kb[:8]))
 	return nil
 }
 
+// ReadUvarint reads an unsigned LEB128-encoded integer into `dst`, as used by
+// protobuf, DWARF and WebAssembly (and `binary.PutUvarint`).
+//
+// An error is returned if the encoding exceeds the maximum varint length
+// (see `SetMaxVarintLen`) or overflows a 64-bit integer.
+func (b *Reader) ReadUvarint(dst *uint64) error {
+	if b.source == nil {
+		return b.newError("ReadUvarint", ErrNilSource)
+	}
+	return b.readUvarint("ReadUvarint", dst)
+}
+
+// readUvarint is the implementation of `ReadUvarint`, describing any failure
+// as an `*Error` for the operation `op`.
+func (b *Reader) readUvarint(op string, dst *uint64) error {
+	start := b.pos
+	x := uint64(0)
+	s := uint(0)
+	max := b.getMaxVarintLen()
+	for i := 0; i < max; i++ {
+		if err := b.read(b._1kb[:1]); err != nil {
+			return &Error{Op: op, Offset: start, Err: err}
+		}
+		c := b._1kb[0]
+		if c < 0x80 {
+			if i == binary.MaxVarintLen64-1 && c > 1 {
+				return &Error{Op: op, Offset: start, Err: errVarintOverflow}
+			}
+			*dst = x | uint64(c)<<s
+			return nil
+		}
+		x |= uint64(c&0x7F) << s
+		s += 7
+	}
+	if max == binary.MaxVarintLen64 {
+		return &Error{Op: op, Offset: start, Err: errVarintOverflow}
+	}
+	return &Error{Op: op, Offset: start, Err: fmt.Errorf("%w: varint exceeds %d bytes", ErrTooLong, max)}
+}
+
+// ReadVarint reads a signed, zig-zag LEB128-encoded integer into `dst`
+// (as written by `binary.PutVarint`).
+func (b *Reader) ReadVarint(dst *int64) error {
+	if b.source == nil {
+		return b.newError("ReadVarint", ErrNilSource)
+	}
+	ux := uint64(0)
+	if err := b.readUvarint("ReadVarint", &ux); err != nil {
+		return err
+	}
+	x := int64(ux >> 1)
+	if ux&1 != 0 {
+		x = ^x
+	}
+	*dst = x
+	return nil
+}
+
 // Discard reads `n` bytes into a discarded buffer.
 func (b *Reader) Discard(n int64) error {
 	if b.source == nil {
-		return fmt.Errorf("Discard(%d): reader is nil", n)
+		return b.newError("Discard", ErrNilSource)
+	}
+	if n < 0 {
+		return b.newError("Discard", ErrNegativeLength)
+	}
+	start := b.pos
+	if err := b.discard(n); err != nil {
+		return &Error{Op: "Discard", Offset: start, Requested: int(n), Got: int(b.pos - start), Err: err}
+	}
+	return nil
+}
+
+// discard is the implementation of `Discard`, returning errors from the
+// source as-is.
+func (b *Reader) discard(n int64) error {
+	if b.buffered() > 0 {
+		// skip over bytes already read ahead without copying them
+		b.i = b.buffered()
+		if int64(b.i) > n {
+			b.i = int(n)
+		}
+		b.consume(b.i)
+		n -= int64(b.i)
 	}
 	b.i64 = n
 	if b.i64 <= 1024 { // shortcut
-		return b.ReadBytes(b._1kb[:n])
+		return b.read(b._1kb[:n])
 	}
 	// cut away at `n` until we have <= 1024 bytes remaining to discard
 	for b.i64 > 1024 {
-		if b.err = b.ReadBytes(b._1kb[:]); b.err != nil {
-			return b.err
+		if err := b.read(b._1kb[:]); err != nil {
+			return err
 		}
 		b.i64 -= 1024
 	}
 	// and then discard the rest
 	// this function should have caused zero allocs.
-	return b.ReadBytes(b._1kb[:b.i64])
+	return b.read(b._1kb[:b.i64])
 }
 
-// numUnusedPeekedBytes returns the number of bytes that have been peeked
-// but not consumed in a subsequent call to the reader.
-func (b *Reader) numUnusedPeekedBytes() int {
-	return b.nPeeked - b.peekPos
-}
-
-// Peek returns the next n bytes without advancing the reader.
-// If the operation cannot fully write to `dst`, it will return an error.
+// Peek reads the next `len(dst)` bytes into `dst` without advancing the
+// reader. If the operation cannot fully write to `dst`, it will return an
+// error.
+//
+// Peeked bytes are held in the reader's read-ahead buffer until consumed, and
+// so at most the read-ahead limit (see `SetReadAheadLimit`) may be peeked at
+// once; attempting to peek more returns an error wrapping `ErrTooLong`. No
+// more bytes are read from the source than are needed to satisfy `dst`.
 func (b *Reader) Peek(dst []byte) error {
 	if b.source == nil {
-		return fmt.Errorf("Peek([%d]byte): reader is nil", len(dst))
+		return b.newError("Peek", ErrNilSource)
 	}
-	b.i = len(dst)
 	// shortcut if `dst` has a length of zero
-	if b.i == 0 {
+	if len(dst) == 0 {
 		return nil
 	}
-	// we may have already peeked bytes
-	if b.numUnusedPeekedBytes() > 0 {
+	if len(dst) > b.readAheadLimit() {
+		return b.newError("Peek", fmt.Errorf("%w: %d bytes exceeds the read-ahead limit of %d", ErrTooLong, len(dst), b.readAheadLimit()))
+	}
+	if err := b.fill(len(dst)); err != nil {
+		return &Error{Op: "Peek", Offset: b.pos, Requested: len(dst), Got: b.buffered(), Err: err}
+	}
+	copy(dst, b.buf[b.off:])
+	return nil
+}
 
-		// we have peeked some bytes.
-		// therefore, they should be used before reading from `source`
-		if b.numUnusedPeekedBytes() >= b.i {
-			// unused bytes in peek buffer exceeed the destination length,
-			// so use up what we can
-			copy(dst, b.peekBuffer[b.peekPos:b.peekPos+b.i])
-			return nil
+// Next returns the next `n` bytes, advancing the reader past them, without
+// copying them into a slice of the caller's. The returned slice aliases the
+// reader's internal buffer, and so is only valid until the next call to the
+// reader; it must not be modified.
+//
+// For readers created by `NewReaderBytes` (and their sub-readers), the
+// returned slice is instead a direct sub-slice of the original source, which
+// remains valid indefinitely, and no bytes are copied at all.
+//
+// As with `Peek`, at most the read-ahead limit may be requested from other
+// sources. If fewer than `n` bytes remain, an error is returned and the
+// reader is not advanced.
+func (b *Reader) Next(n int) ([]byte, error) {
+	if b.source == nil {
+		return nil, b.newError("Next", ErrNilSource)
+	}
+	if n < 0 {
+		return nil, b.newError("Next", ErrNegativeLength)
+	}
+	if p, ok := b.nextBytes(n); ok {
+		return p, nil
+	}
+	if n > b.readAheadLimit() {
+		return nil, b.newError("Next", fmt.Errorf("%w: %d bytes exceeds the read-ahead limit of %d", ErrTooLong, n, b.readAheadLimit()))
+	}
+	if err := b.fill(n); err != nil {
+		return nil, &Error{Op: "Next", Offset: b.pos, Requested: n, Got: b.buffered(), Err: err}
+	}
+	p := b.buf[b.off : b.off+n : b.off+n]
+	b.consume(n)
+	return p, nil
+}
+
+// nextBytes is the zero-copy implementation of `Next` for readers of a byte
+// slice, returning false if the reader is not one or too few bytes remain.
+func (b *Reader) nextBytes(n int) ([]byte, bool) {
+	switch {
+	case b.marked:
+		// the bytes must be read through the buffer to be retained
+		return nil, false
+	case b.srcBytes != nil:
+		br := b.source.(*bytes.Reader)
+		// any buffered bytes immediately precede those unread by `br`
+		start := len(b.srcBytes) - br.Len() - b.buffered()
+		if n > len(b.srcBytes)-start {
+			return nil, false
+		}
+		m := b.buffered()
+		if m > n {
+			m = n
+		}
+		b.consume(m)
+		if m < n {
+			br.Seek(int64(n-m), io.SeekCurrent)
+			b.tap(b.srcBytes[start+m : start+n])
+			b.pos += int64(n - m)
+		}
+		return b.srcBytes[start : start+n : start+n], true
+	case b.container != nil && b.buffered() == 0:
+		c := b.container
+		if int64(n) > c.remaining {
+			return nil, false
+		}
+		p, ok := c.parent.nextBytes(n)
+		if ok {
+			b.tap(p)
+			c.remaining -= int64(n)
+			b.pos += int64(n)
 		}
-		// more bytes are requested than available in peek buffer
-		// fulfill partially from peek buffer, then `io.ReadFull` the rest
-		copy(dst, b.peekBuffer[b.peekPos:b.nPeeked])
+		return p, ok
 	}
-	nRead := b.i - b.numUnusedPeekedBytes()
+	return nil, false
+}
 
-	// determine whether we need to grow buffer
-	b.i = ((b.nPeeked + nRead) - len(b.peekBuffer)) // if > 0 then we need to grow
+// SetReadAheadLimit sets the maximum number of bytes the reader may buffer
+// ahead of its position, which bounds the memory used by `Peek` and
+// `ReadCString`. The default, used if `n` is less than one, is
+// `DefaultReadAheadLimit`.
+//
+// Bytes already buffered are retained, even if they exceed a reduced limit.
+func (b *Reader) SetReadAheadLimit(n int) {
+	if n < 0 {
+		n = 0
+	}
+	b.maxBuf = n
+}
 
-	if b.i > 0 {
-		// grow by a minimum of 1kb to reduce overhead
-		if b.i <= 64 {
-			b.peekBuffer = append(b.peekBuffer, make([]byte, 64)...)
-		} else {
-			b.peekBuffer = append(b.peekBuffer, make([]byte, b.i)...)
-		}
+// readAheadLimit returns the maximum number of bytes which may be buffered,
+// falling back to `DefaultReadAheadLimit` if none has been set.
+func (b *Reader) readAheadLimit() int {
+	if b.maxBuf == 0 {
+		return DefaultReadAheadLimit
 	}
+	return b.maxBuf
+}
 
-	if _, b.err = io.ReadFull(b.source, b.peekBuffer[b.nPeeked:b.nPeeked+nRead]); b.err != nil {
-		return b.err
+// buffered returns the number of bytes which have been read from the source
+// but not yet consumed.
+func (b *Reader) buffered() int {
+	return len(b.buf) - b.off
+}
+
+// consume advances the reader past the next `n` buffered bytes.
+func (b *Reader) consume(n int) {
+	b.tap(b.buf[b.off : b.off+n])
+	b.advance(n)
+}
+
+// advance is as `consume`, but without writing the bytes to any active hash.
+func (b *Reader) advance(n int) {
+	b.off += n
+	b.pos += int64(n)
+	if b.marked && b.off-b.mark > b.retentionLimit() {
+		b.loseMark()
+	}
+	if b.off == len(b.buf) && !b.marked {
+		// reuse the buffer from its start
+		b.buf = b.buf[:0]
+		b.off = 0
 	}
+}
 
-	copy(dst[b.numUnusedPeekedBytes():], b.peekBuffer[b.nPeeked:b.nPeeked+nRead])
-	b.nPeeked += nRead
+// fill reads from the source until at least `n` bytes are buffered, returning
+// errors from the source as-is. Only the bytes needed are requested, so that
+// the source is not read beyond what the caller has asked for.
+func (b *Reader) fill(n int) error {
+	need := n - b.buffered()
+	if need <= 0 {
+		return nil
+	}
+	b.makeRoom(n)
+	m, err := io.ReadFull(b.source, b.buf[len(b.buf):len(b.buf)+need])
+	b.buf = b.buf[:len(b.buf)+m]
+	if err == io.EOF && b.buffered() > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	return b.bounded(err)
+}
+
+// makeRoom ensures the buffer has capacity for `n` unconsumed bytes, along
+// with any retained since the mark (see `Mark`), moving them to its start or
+// growing it as necessary.
+func (b *Reader) makeRoom(n int) {
+	if b.off+n <= cap(b.buf) {
+		return
+	}
+	keep := b.off
+	if b.marked {
+		keep = b.mark
+	}
+	retained := b.off - keep
+	if retained+n <= cap(b.buf) {
+		// reclaim the space occupied by consumed bytes
+		b.buf = b.buf[:copy(b.buf, b.buf[keep:])]
+		b.off, b.mark = retained, 0
+		return
+	}
+	size := 2 * cap(b.buf)
+	if size < 64 {
+		size = 64
+	}
+	if limit := retained + b.readAheadLimit(); size > limit {
+		size = limit
+	}
+	if size < retained+n {
+		size = retained + n
+	}
+	buf := make([]byte, len(b.buf)-keep, size)
+	copy(buf, b.buf[keep:])
+	b.buf = buf
+	b.off, b.mark = retained, 0
+}
+
+// Seek satisfies `io.Seeker`, moving the reader to `offset` according to
+// `whence` (`io.SeekStart`, `io.SeekCurrent` or `io.SeekEnd`).
+//
+// Relative seeks that land within bytes which have already been peeked are
+// served from the read-ahead buffer; all other seeks are passed to the source,
+// which must implement `io.Seeker`, and empty the buffer. In the latter
+// case the reader position becomes the absolute offset reported by the source.
+//
+// `Seek(0, io.SeekCurrent)` returns the current position, and is permitted
+// even when the source cannot seek.
+func (b *Reader) Seek(offset int64, whence int) (int64, error) {
+	if b.source == nil {
+		return 0, b.newError("Seek", ErrNilSource)
+	}
+	if whence == io.SeekCurrent && offset >= 0 && offset <= int64(b.buffered()) {
+		// skip over bytes already held in the buffer; as with any seek, they
+		// are not hashed
+		b.advance(int(offset))
+		return b.pos, nil
+	}
+	seeker, ok := b.source.(io.Seeker)
+	if !ok {
+		return 0, b.newError("Seek", errNotSeeker)
+	}
+	if whence == io.SeekCurrent {
+		// the source is ahead of the reader by any buffered bytes
+		offset -= int64(b.buffered())
+	}
+	abs, err := seeker.Seek(offset, whence)
+	if err != nil {
+		return 0, b.newError("Seek", err)
+	}
+	b.loseMark()
+	b.buf = b.buf[:0]
+	b.off = 0
+	b.pos = abs
+	return abs, nil
+}
+
+// SetPosition moves the reader to the absolute offset `pos` within the
+// source, which must implement `io.Seeker`.
+func (b *Reader) SetPosition(pos int64) error {
+	_, err := b.Seek(pos, io.SeekStart)
+	return err
+}
+
+// SubReader creates a new `Reader` limited to the next `n` bytes of `b`, for
+// parsing a nested container (or chunk) of declared length. The sub-reader
+// inherits the byte order of `b`.
+//
+// Reads which would extend beyond the container return `ErrExceededContainer`
+// rather than consuming bytes belonging to its siblings. `Read` alone instead
+// returns `io.EOF` at the end of the container, as any `io.Reader` would, so
+// that the sub-reader may be used with `io.Copy` and the like. Bytes consumed
+// by the sub-reader are consumed from `b`, so `b` should not be read from
+// until the sub-reader is finished with; `SkipRemaining` may then be used to
+// move `b` past any bytes the sub-reader did not consume.
+func (b *Reader) SubReader(n int64) (Reader, error) {
+	if b.source == nil {
+		return Reader{}, b.newError("SubReader", ErrNilSource)
+	}
+	if n < 0 {
+		return Reader{}, b.newError("SubReader", ErrNegativeLength)
+	}
+	if b.container != nil && n > b.Remaining() {
+		return Reader{}, b.newError("SubReader", ErrExceededContainer)
+	}
+	container := &containerSource{parent: b, limit: n, remaining: n}
+	sub := NewReader(container, b.bo)
+	sub.container = container
+	sub.base = b.GetAbsolutePosition()
+	sub.maxVarintLen = b.maxVarintLen
+	sub.maxBuf = b.maxBuf
+	sub.maxRetain = b.maxRetain
+	return sub, nil
+}
+
+// Remaining returns the number of bytes left unread in a sub-reader's
+// container, or -1 if the reader is not a sub-reader.
+func (b *Reader) Remaining() int64 {
+	if b.container == nil {
+		return -1
+	}
+	return b.container.limit - b.pos
+}
+
+// SkipRemaining discards whatever remains of a sub-reader's container, such
+// that its parent is positioned immediately after the container.
+func (b *Reader) SkipRemaining() error {
+	if b.container == nil {
+		return b.newError("SkipRemaining", errNotSubReader)
+	}
+	if err := b.container.parent.Discard(b.container.remaining); err != nil {
+		return err
+	}
+	b.container.remaining = 0
+	b.loseMark()
+	b.buf = b.buf[:0]
+	b.off = 0
+	b.pos = b.container.limit
 	return nil
 }
 
+// GetAbsolutePosition returns the current reader offset relative to the
+// outermost reader; for sub-readers this includes the offset of the
+// container, whereas `GetPosition` is relative to the container.
+func (b *Reader) GetAbsolutePosition() int64 {
+	return b.base + b.pos
+}
+
+// Read satisfies `io.Reader`, consuming at most the remaining bytes of the
+// container from the parent reader.
+func (c *containerSource) Read(p []byte) (int, error) {
+	if c.remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > c.remaining {
+		p = p[:c.remaining]
+	}
+	start := c.parent.pos
+	err := c.parent.ReadBytes(p)
+	if e, ok := err.(*Error); ok {
+		// the sub-reader describes the failure itself
+		err = e.Err
+	}
+	n := int(c.parent.pos - start)
+	c.remaining -= int64(n)
+	return n, err
+}
+
+// bounded returns `ErrExceededContainer` in place of `err` if it reports the
+// end of a sub-reader's container, whose source otherwise ends as any other
+// `io.Reader` does, with `io.EOF`.
+func (b *Reader) bounded(err error) error {
+	if (err == io.EOF || err == io.ErrUnexpectedEOF) && b.container != nil && b.container.remaining == 0 {
+		return ErrExceededContainer
+	}
+	return err
+}
+
 // Reset resets the reader position and source `io.Reader` to `source`
 func (b *Reader) Reset(source io.Reader, bo binary.ByteOrder) {
 	b.pos = 0
 	b.source = source
 	b.bo = bo
-	b.peekPos = 0
-	b.nPeeked = 0
+	b.buf = b.buf[:0]
+	b.off = 0
+	b.srcBytes = nil
+	b.marked = false
+	b.markLost = false
+	b.base = 0
+	b.container = nil
+	b.alignBase = 0
+	b.hashes = b.hashes[:0]
+	b.err = nil
 }
 
 // NewReader creates a new `Reader` encapsulating the given `source`,
@@ -290,8 +824,7 @@ func (b *Reader) Reset(source io.Reader, bo binary.ByteOrder) {
 // manually creating an instance (i.e. `br := Reader{}`)
 func NewReader(source io.Reader, bo binary.ByteOrder) Reader {
 	br := Reader{
-		source:     source,
-		peekBuffer: make([]byte, 64),
+		source: source,
 	}
 	br.bo = bo
 	return br
@@ -306,7 +839,166 @@ func NewReader(source io.Reader, bo binary.ByteOrder) Reader {
 // For futureproofing, it is suggested to use these constructors rather than
 // manually creating an instance (i.e. `br := Reader{}`)
 func NewReaderBytes(source []byte, bo binary.ByteOrder) Reader {
-	return NewReader(bytes.NewReader(source), bo)
+	br := NewReader(bytes.NewReader(source), bo)
+	br.srcBytes = source
+	return br
+}
+
+/*
+===============================================================================
+    Reader: Sticky Errors
+===============================================================================
+*/
+
+// The following methods provide an alternative, "fluent", style of reading
+// in which errors need not be checked after every call. Similar to
+// `bufio.Scanner`, the first error encountered is recorded by the reader, and
+// every subsequent call becomes a no-op returning the zero value. Once the
+// caller has finished, `Err` reports the error (if any):
+//
+//	magic := br.Uint32()
+//	count := br.Uint16()
+//	scale := br.Float64()
+//	if err := br.Err(); err != nil {
+//		return err
+//	}
+//
+// Only the methods in this section are affected by a recorded error; the
+// `Read*` methods continue to operate as normal. The error is cleared by
+// `Reset`.
+
+// Byte reads one byte, recording any error for `Err`.
+func (b *Reader) Byte() (v byte) {
+	if b.err == nil {
+		b.latch(b.ReadByte(&v))
+	}
+	return
+}
+
+// Uint16 reads an unsigned 16-bit integer according to the current byte
+// order, recording any error for `Err`.
+func (b *Reader) Uint16() (v uint16) {
+	if b.err == nil {
+		b.latch(b.ReadUint16(&v))
+	}
+	return
+}
+
+// Uint32 reads an unsigned 32-bit integer according to the current byte
+// order, recording any error for `Err`.
+func (b *Reader) Uint32() (v uint32) {
+	if b.err == nil {
+		b.latch(b.ReadUint32(&v))
+	}
+	return
+}
+
+// Uint64 reads an unsigned 64-bit integer according to the current byte
+// order, recording any error for `Err`.
+func (b *Reader) Uint64() (v uint64) {
+	if b.err == nil {
+		b.latch(b.ReadUint64(&v))
+	}
+	return
+}
+
+// Int8 reads a signed 8-bit integer, recording any error for `Err`.
+func (b *Reader) Int8() (v int8) {
+	if b.err == nil {
+		b.latch(b.ReadInt8(&v))
+	}
+	return
+}
+
+// Int16 reads a signed 16-bit integer according to the current byte order,
+// recording any error for `Err`.
+func (b *Reader) Int16() (v int16) {
+	if b.err == nil {
+		b.latch(b.ReadInt16(&v))
+	}
+	return
+}
+
+// Int32 reads a signed 32-bit integer according to the current byte order,
+// recording any error for `Err`.
+func (b *Reader) Int32() (v int32) {
+	if b.err == nil {
+		b.latch(b.ReadInt32(&v))
+	}
+	return
+}
+
+// Int64 reads a signed 64-bit integer according to the current byte order,
+// recording any error for `Err`.
+func (b *Reader) Int64() (v int64) {
+	if b.err == nil {
+		b.latch(b.ReadInt64(&v))
+	}
+	return
+}
+
+// Float32 reads a 32-bit IEEE 754 float according to the current byte
+// order, recording any error for `Err`.
+func (b *Reader) Float32() (v float32) {
+	if b.err == nil {
+		b.latch(b.ReadFloat32(&v))
+	}
+	return
+}
+
+// Float64 reads a 64-bit IEEE 754 float according to the current byte
+// order, recording any error for `Err`.
+func (b *Reader) Float64() (v float64) {
+	if b.err == nil {
+		b.latch(b.ReadFloat64(&v))
+	}
+	return
+}
+
+// Uvarint reads an unsigned varint, recording any error for `Err`.
+func (b *Reader) Uvarint() (v uint64) {
+	if b.err == nil {
+		b.latch(b.ReadUvarint(&v))
+	}
+	return
+}
+
+// Varint reads a signed (zig-zag encoded) varint, recording any error for
+// `Err`.
+func (b *Reader) Varint() (v int64) {
+	if b.err == nil {
+		b.latch(b.ReadVarint(&v))
+	}
+	return
+}
+
+// Fill reads exactly `len(dst)` bytes into `dst`, recording any error for
+// `Err`. `dst` is left untouched if an error has already been recorded.
+func (b *Reader) Fill(dst []byte) {
+	if b.err == nil {
+		b.latch(b.ReadBytes(dst))
+	}
+}
+
+// Skip discards `n` bytes, recording any error for `Err`.
+func (b *Reader) Skip(n int64) {
+	if b.err == nil {
+		b.latch(b.Discard(n))
+	}
+}
+
+// Err returns the first error encountered by the sticky-error methods (such
+// as `Uint32`) since the reader was created or reset. The error is an
+// `*Error`, whose `Offset` is the position at which the failing read began.
+func (b *Reader) Err() error {
+	return b.err
+}
+
+// latch records `err`, if any.
+func (b *Reader) latch(err error) {
+	if err != nil {
+		b.err = err
+	}
 }
 
 /*
@@ -318,10 +1010,10 @@ func NewReaderBytes(source []byte, bo binary.ByteOrder) Reader {
 // WriteByte writes a byte
 func (b *Writer) WriteByte(src byte) error {
 	if b.dest == nil {
-		return fmt.Errorf("WriteByte(%02X): writer is nil", src)
+		return b.newError("WriteByte", ErrNilSource)
 	}
 	b._1kb[0] = src
-	return b.WriteBytes(b._1kb[:1])
+	return b.writeFull("WriteByte", b._1kb[:1])
 }
 
 // Write satisfies the Liskov Subsitution Principle of its base `io.Writer`
@@ -330,7 +1022,16 @@ func (b *Writer) Write(p []byte) (n int, err error) {
 	if len(p) == 0 {
 		return 0, nil
 	}
+	if b.buf != nil || b.open > 0 || len(b.held) > 0 {
+		// keep `p` in order with the buffered and held writes
+		start := b.pos
+		err = b.write(p)
+		return int(b.pos - start), err
+	}
 	n, err = b.dest.Write(p)
+	if len(b.hashes) > 0 && n > 0 && n <= len(p) {
+		b.tap(p[:n])
+	}
 	b.pos += int64(n)
 	return
 }
@@ -338,117 +1039,258 @@ func (b *Writer) Write(p []byte) (n int, err error) {
 // WriteBytes writes all bytes from `src`
 func (b *Writer) WriteBytes(src []byte) error {
 	if b.dest == nil {
-		return errors.New("WriteBytes([]byte): writer is nil")
+		return b.newError("WriteBytes", ErrNilSource)
 	}
+	return b.writeFull("WriteBytes", src)
+}
+
+// writeFull writes all bytes from `src`, describing any failure as an `*Error`
+// for the operation `op`.
+func (b *Writer) writeFull(op string, src []byte) error {
+	start := b.pos
+	if err := b.write(src); err != nil {
+		return &Error{Op: op, Offset: start, Requested: len(src), Got: int(b.pos - start), Err: err}
+	}
+	return nil
+}
+
+// write is the implementation of `WriteBytes`, returning errors from the
+// destination as-is. The destination must not be nil.
+func (b *Writer) write(src []byte) error {
 	// shortcut if `src` has a length of zero
 	if len(src) == 0 {
 		return nil
 	}
+	if b.open > 0 {
+		b.tap(src)
+		b.held = append(b.held, src...)
+		b.pos += int64(len(src))
+		return nil
+	}
+	if len(b.held) > 0 {
+		if err := b.release(); err != nil {
+			return err
+		}
+	}
+	if b.buf != nil {
+		if len(src) > cap(b.buf)-len(b.buf) {
+			if err := b.flush(); err != nil {
+				return err
+			}
+		}
+		if len(src) <= cap(b.buf)-len(b.buf) {
+			b.tap(src)
+			b.buf = append(b.buf, src...)
+			b.pos += int64(len(src))
+			return nil
+		}
+		// too large to be worth buffering
+	}
 	b.i, b.err = b.dest.Write(src)
+	if len(b.hashes) > 0 && b.i > 0 && b.i <= len(src) {
+		b.tap(src[:b.i])
+	}
 	b.pos += int64(b.i)
-	if b.err != nil {
-		return b.err
+	if b.err == nil && b.i < len(src) {
+		b.err = io.ErrShortWrite
 	}
-	return nil
+	return b.err
 }
 
 // WriteUint16 writes an unsigned 16-bit integer according to the current byte order.
 func (b *Writer) WriteUint16(src uint16) error {
 	if b.dest == nil {
-		return fmt.Errorf("WriteUint16(%08X): writer is nil", src)
+		return b.newError("WriteUint16", ErrNilSource)
 	}
 	if b.bo == nil {
-		return fmt.Errorf("WriteUint16(%08X): ByteOrder is not set", src)
+		return b.newError("WriteUint16", ErrNilByteOrder)
 	}
 	b.bo.PutUint16(b._1kb[:2], src)
-	return b.WriteBytes(b._1kb[:2])
+	return b.writeFull("WriteUint16", b._1kb[:2])
 }
 
 // WriteUint32 writes an unsigned 32-bit integer according to the current byte order.
 func (b *Writer) WriteUint32(src uint32) error {
 	if b.dest == nil {
-		return fmt.Errorf("WriteUint32(%016X): writer is nil", src)
+		return b.newError("WriteUint32", ErrNilSource)
 	}
 	if b.bo == nil {
-		return fmt.Errorf("WriteUint32(%016X): ByteOrder is not set", src)
+		return b.newError("WriteUint32", ErrNilByteOrder)
 	}
 	b.bo.PutUint32(b._1kb[:4], src)
-	return b.WriteBytes(b._1kb[:4])
+	return b.writeFull("WriteUint32", b._1kb[:4])
 }
 
 // WriteUint64 writes an unsigned 64-bit integer according to the current byte order.
 func (b *Writer) WriteUint64(src uint64) error {
 	if b.dest == nil {
-		return fmt.Errorf("WriteUint64(%032X): writer is nil", src)
+		return b.newError("WriteUint64", ErrNilSource)
 	}
 	if b.bo == nil {
-		return fmt.Errorf("WriteUint64(%032X): ByteOrder is not set", src)
+		return b.newError("WriteUint64", ErrNilByteOrder)
 	}
 	b.bo.PutUint64(b._1kb[:8], src)
-	return b.WriteBytes(b._1kb[:8])
+	return b.writeFull("WriteUint64", b._1kb[:8])
+}
+
+// WriteInt8 writes a signed 8-bit integer.
+//
+// As a single byte has no endianness, the current byte order is not consulted.
+func (b *Writer) WriteInt8(src int8) error {
+	if b.dest == nil {
+		return b.newError("WriteInt8", ErrNilSource)
+	}
+	b._1kb[0] = byte(src)
+	return b.writeFull("WriteInt8", b._1kb[:1])
+}
+
+// WriteInt16 writes a signed 16-bit integer according to the current byte order.
+func (b *Writer) WriteInt16(src int16) error {
+	if b.dest == nil {
+		return b.newError("WriteInt16", ErrNilSource)
+	}
+	if b.bo == nil {
+		return b.newError("WriteInt16", ErrNilByteOrder)
+	}
+	b.bo.PutUint16(b._1kb[:2], uint16(src))
+	return b.writeFull("WriteInt16", b._1kb[:2])
+}
+
+// WriteInt32 writes a signed 32-bit integer according to the current byte order.
+func (b *Writer) WriteInt32(src int32) error {
+	if b.dest == nil {
+		return b.newError("WriteInt32", ErrNilSource)
+	}
+	if b.bo == nil {
+		return b.newError("WriteInt32", ErrNilByteOrder)
+	}
+	b.bo.PutUint32(b._1kb[:4], uint32(src))
+	return b.writeFull("WriteInt32", b._1kb[:4])
+}
+
+// WriteInt64 writes a signed 64-bit integer according to the current byte order.
+func (b *Writer) WriteInt64(src int64) error {
+	if b.dest == nil {
+		return b.newError("WriteInt64", ErrNilSource)
+	}
+	if b.bo == nil {
+		return b.newError("WriteInt64", ErrNilByteOrder)
+	}
+	b.bo.PutUint64(b._1kb[:8], uint64(src))
+	return b.writeFull("WriteInt64", b._1kb[:8])
 }
 
 // WriteFloat32 writes a 32-bit IEEE 754 floating-point integer
 // according to the current byte order.
 func (b *Writer) WriteFloat32(src float32) error {
 	if b.dest == nil {
-		return fmt.Errorf("WriteFloat32(%f): writer is nil", src)
+		return b.newError("WriteFloat32", ErrNilSource)
 	}
 	if b.bo == nil {
-		return fmt.Errorf("WriteFloat32(%f): ByteOrder is not set", src)
+		return b.newError("WriteFloat32", ErrNilByteOrder)
 	}
 	b.bo.PutUint32(b._1kb[:4], math.Float32bits(src))
-	return b.WriteBytes(b._1kb[:4])
+	return b.writeFull("WriteFloat32", b._1kb[:4])
 }
 
 // WriteFloat64 writes a 64-bit IEEE 754 floating-point integer
 // according to the current byte order.
 func (b *Writer) WriteFloat64(src float64) error {
 	if b.dest == nil {
-		return fmt.Errorf("WriteFloat64(%f): writer is nil", src)
+		return b.newError("WriteFloat64", ErrNilSource)
 	}
 	if b.bo == nil {
-		return fmt.Errorf("WriteFloat64(%f): ByteOrder is not set", src)
+		return b.newError("WriteFloat64", ErrNilByteOrder)
 	}
 	b.bo.PutUint64(b._1kb[:8], math.Float64bits(src))
-	return b.WriteBytes(b._1kb[:8])
+	return b.writeFull("WriteFloat64", b._1kb[:8])
+}
+
+// WriteUvarint writes an unsigned LEB128-encoded integer, as used by
+// protobuf, DWARF and WebAssembly (and `binary.ReadUvarint`).
+//
+// An error is returned if the encoding exceeds the maximum varint length
+// (see `SetMaxVarintLen`).
+func (b *Writer) WriteUvarint(src uint64) error {
+	if b.dest == nil {
+		return b.newError("WriteUvarint", ErrNilSource)
+	}
+	b.i = binary.PutUvarint(b._1kb[:binary.MaxVarintLen64], src)
+	if b.i > b.getMaxVarintLen() {
+		return b.newError("WriteUvarint", fmt.Errorf("%w: encoding exceeds %d bytes", ErrTooLong, b.getMaxVarintLen()))
+	}
+	return b.writeFull("WriteUvarint", b._1kb[:b.i])
+}
+
+// WriteVarint writes a signed, zig-zag LEB128-encoded integer
+// (as read by `binary.ReadVarint`).
+func (b *Writer) WriteVarint(src int64) error {
+	if b.dest == nil {
+		return b.newError("WriteVarint", ErrNilSource)
+	}
+	b.i = binary.PutVarint(b._1kb[:binary.MaxVarintLen64], src)
+	if b.i > b.getMaxVarintLen() {
+		return b.newError("WriteVarint", fmt.Errorf("%w: encoding exceeds %d bytes", ErrTooLong, b.getMaxVarintLen()))
+	}
+	return b.writeFull("WriteVarint", b._1kb[:b.i])
 }
 
 // ZeroFill writes `n` null-bytes.
 func (b *Writer) ZeroFill(n int64) error {
 	if b.dest == nil {
-		return fmt.Errorf("ZeroFill(%d): writer is nil", n)
+		return b.newError("ZeroFill", ErrNilSource)
 	}
 	// cleanse input to procedure
 	if n < 0 {
-		return fmt.Errorf("ZeroFill(%d): negative length", n)
+		return b.newError("ZeroFill", ErrNegativeLength)
 	}
 	// shortcut if `n` is zero
 	if n == 0 {
 		return nil
 	}
+	start := b.pos
+	if err := b.zeroFill(n); err != nil {
+		return &Error{Op: "ZeroFill", Offset: start, Requested: int(n), Got: int(b.pos - start), Err: err}
+	}
+	return nil
+}
+
+// zeroFill is the implementation of `ZeroFill`, returning errors from the
+// destination as-is.
+func (b *Writer) zeroFill(n int64) error {
 	b.i64 = n
 	if b.i64 <= 1024 { // shortcut
-		return b.WriteBytes(b.null1kb[:n])
+		return b.write(b.null1kb[:n])
 	}
 	// cut away at `n` until we have <= 1024 bytes remaining to fill
 	for b.i64 > 1024 {
-		if b.err = b.WriteBytes(b.null1kb[:]); b.err != nil {
-			return b.err
+		if err := b.write(b.null1kb[:]); err != nil {
+			return err
 		}
 		b.i64 -= 1024
 	}
-	// and then discard the rest
+	// and then fill the rest
 	// this function should have caused zero allocs.
-	return b.WriteBytes(b.null1kb[:b.i64])
-
+	return b.write(b.null1kb[:b.i64])
 }
 
-// Reset resets the writer position and source `io.Writer` to `dest`
+// Reset resets the writer position and source `io.Writer` to `dest`.
+// Unpatched reservations (see `Reserve`) are discarded, along with any
+// writes held back on their behalf, as are unflushed writes of a buffered
+// writer; call `Flush` first to keep them. A buffered writer remains
+// buffered.
 func (b *Writer) Reset(dest io.Writer, bo binary.ByteOrder) {
 	b.pos = 0
 	b.dest = dest
+	if b.buf != nil {
+		b.buf = b.buf[:0]
+	}
 	b.bo = bo
+	b.alignBase = 0
+	b.held = b.held[:0]
+	b.open = 0
+	b.epoch++
+	b.hashes = b.hashes[:0]
 }
 
 // NewWriter creates a new `Writer` targetted at the given `dest`,
@@ -462,6 +1304,63 @@ func NewWriter(dest io.Writer, bo binary.ByteOrder) (bw Writer) {
 	return
 }
 
+// NewBufferedWriter is as `NewWriter`, but accumulates writes in a buffer of
+// `size` bytes, or `DefaultBufferSize` if `size` is not positive, which is
+// passed to `dest` once full. This greatly reduces the number of calls made
+// to `dest`, and so is preferable for destinations such as an `*os.File` or
+// `net.Conn`.
+//
+// `Flush` must be called once writing is complete. `GetPosition` includes
+// buffered bytes.
+func NewBufferedWriter(dest io.Writer, bo binary.ByteOrder, size int) (bw Writer) {
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+	bw = NewWriter(dest, bo)
+	bw.buf = make([]byte, 0, size)
+	return
+}
+
+// Flush writes any buffered bytes to the destination. Bytes held back for
+// unpatched reservations (see `Reserve`) are not written until the last of
+// them is patched.
+func (b *Writer) Flush() error {
+	if b.dest == nil {
+		return b.newError("Flush", ErrNilSource)
+	}
+	var err error
+	if b.open == 0 && len(b.held) > 0 {
+		err = b.release()
+	} else {
+		err = b.flush()
+	}
+	if err != nil {
+		// report the offset of the first unwritten byte
+		return &Error{Op: "Flush", Offset: b.pos - int64(b.Buffered()), Err: err}
+	}
+	return nil
+}
+
+// Buffered returns the number of bytes which have been written but not yet
+// passed to the destination.
+func (b *Writer) Buffered() int {
+	return len(b.buf) + len(b.held)
+}
+
+// flush writes the contents of the buffer to the destination, retaining any
+// which could not be written.
+func (b *Writer) flush() error {
+	if len(b.buf) == 0 {
+		return nil
+	}
+	n, err := b.dest.Write(b.buf)
+	if err == nil && n < len(b.buf) {
+		err = io.ErrShortWrite
+	}
+	b.buf = b.buf[:copy(b.buf, b.buf[n:])]
+	return err
+}
+
 /*
 ===============================================================================
     binaryBase
@@ -486,3 +1385,27 @@ func (b *binaryBase) SetByteOrder(bo binary.ByteOrder) {
 func (b *binaryBase) GetByteOrder() binary.ByteOrder {
 	return b.bo
 }
+
+// SetMaxVarintLen sets the maximum number of bytes a varint may be encoded
+// with, which is useful for formats that restrict themselves to (for example)
+// 32-bit varints.
+//
+// `n` is clamped to the range [1, `binary.MaxVarintLen64`]; the default is
+// `binary.MaxVarintLen64`.
+func (b *binaryBase) SetMaxVarintLen(n int) {
+	if n < 1 {
+		n = 1
+	} else if n > binary.MaxVarintLen64 {
+		n = binary.MaxVarintLen64
+	}
+	b.maxVarintLen = n
+}
+
+// getMaxVarintLen returns the maximum encoded varint length, falling back to
+// `binary.MaxVarintLen64` if the interface was not created via a constructor.
+func (b *binaryBase) getMaxVarintLen() int {
+	if b.maxVarintLen == 0 {
+		return binary.MaxVarintLen64
+	}
+	return b.maxVarintLen
+}
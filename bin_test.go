@@ -2,10 +2,20 @@ package bin
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"hash/adler32"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"runtime"
+	"strings"
 	"testing"
+	"unicode/utf16"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -258,6 +268,162 @@ func TestReadUint64Error(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestReadInt8(t *testing.T) {
+	t.Parallel()
+	buf := []byte{0x7F, 0x80, 0xFF}
+	bb := NewReaderBytes(buf, binary.LittleEndian)
+	i8 := int8(0)
+
+	assert.NoError(t, bb.ReadInt8(&i8))
+	assert.Equal(t, int8(127), i8)
+	assert.NoError(t, bb.ReadInt8(&i8))
+	assert.Equal(t, int8(-128), i8)
+	assert.NoError(t, bb.ReadInt8(&i8))
+	assert.Equal(t, int8(-1), i8)
+	assert.Equal(t, int64(3), bb.GetPosition())
+}
+
+func TestReadInt8Error(t *testing.T) {
+	t.Parallel()
+	buf := int8(0)
+
+	// nil reader
+	bb := Reader{}
+	bb.bo = binary.LittleEndian
+	assert.Error(t, bb.ReadInt8(&buf))
+
+	// Reached EOF
+	bb = NewReaderBytes(testBuffer, binary.LittleEndian)
+	bb.source.Read(make([]byte, len(testBuffer)))
+	assert.Error(t, bb.ReadInt8(&buf))
+}
+
+func TestReadInt16(t *testing.T) {
+	t.Parallel()
+	// Little Endian
+	buf := []byte{0xFE, 0xFF, 0xFF, 0x7F}
+	bb := NewReaderBytes(buf, binary.LittleEndian)
+	i16 := int16(0)
+
+	err := bb.ReadInt16(&i16)
+	assert.NoError(t, err)
+	assert.Equal(t, int16(-2), i16)
+
+	err = bb.ReadInt16(&i16)
+	assert.NoError(t, err)
+	assert.Equal(t, int16(32767), i16)
+
+	// Big Endian
+	buf = []byte{0xFF, 0xFE, 0x80, 0x00}
+	bb = NewReaderBytes(buf, binary.BigEndian)
+
+	err = bb.ReadInt16(&i16)
+	assert.NoError(t, err)
+	assert.Equal(t, int16(-2), i16)
+
+	err = bb.ReadInt16(&i16)
+	assert.NoError(t, err)
+	assert.Equal(t, int16(-32768), i16)
+}
+
+func TestReadInt16Error(t *testing.T) {
+	t.Parallel()
+	buf := int16(0)
+
+	// nil reader
+	bb := Reader{}
+	bb.bo = binary.LittleEndian
+	assert.Error(t, bb.ReadInt16(&buf))
+
+	// nil byte order
+	bb = Reader{}
+	bb.source = bytes.NewReader(testBuffer)
+	assert.Error(t, bb.ReadInt16(&buf))
+
+	// Reached EOF during read (partial)
+	bb = NewReaderBytes(testBuffer, binary.LittleEndian)
+	bb.source.Read(make([]byte, len(testBuffer)-1))
+	assert.Error(t, bb.ReadInt16(&buf))
+}
+
+func TestReadInt32(t *testing.T) {
+	t.Parallel()
+	// Little Endian
+	buf := []byte{0xFE, 0xFF, 0xFF, 0xFF}
+	bb := NewReaderBytes(buf, binary.LittleEndian)
+	i32 := int32(0)
+
+	err := bb.ReadInt32(&i32)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(-2), i32)
+
+	// Big Endian
+	buf = []byte{0x80, 0x00, 0x00, 0x01}
+	bb = NewReaderBytes(buf, binary.BigEndian)
+	err = bb.ReadInt32(&i32)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(-2147483647), i32)
+}
+
+func TestReadInt32Error(t *testing.T) {
+	t.Parallel()
+	buf := int32(0)
+
+	// nil reader
+	bb := Reader{}
+	bb.bo = binary.LittleEndian
+	assert.Error(t, bb.ReadInt32(&buf))
+
+	// nil byte order
+	bb = Reader{}
+	bb.source = bytes.NewReader(testBuffer)
+	assert.Error(t, bb.ReadInt32(&buf))
+
+	// Reached EOF during read (partial)
+	bb = NewReaderBytes(testBuffer, binary.LittleEndian)
+	bb.source.Read(make([]byte, len(testBuffer)-3))
+	assert.Error(t, bb.ReadInt32(&buf))
+}
+
+func TestReadInt64(t *testing.T) {
+	t.Parallel()
+	// Little Endian
+	buf := []byte{0xFE, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}
+	bb := NewReaderBytes(buf, binary.LittleEndian)
+	i64 := int64(0)
+
+	err := bb.ReadInt64(&i64)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-2), i64)
+
+	// Big Endian
+	buf = []byte{0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	bb = NewReaderBytes(buf, binary.BigEndian)
+	err = bb.ReadInt64(&i64)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-9223372036854775808), i64)
+}
+
+func TestReadInt64Error(t *testing.T) {
+	t.Parallel()
+	buf := int64(0)
+
+	// nil reader
+	bb := Reader{}
+	bb.bo = binary.LittleEndian
+	assert.Error(t, bb.ReadInt64(&buf))
+
+	// nil byte order
+	bb = Reader{}
+	bb.source = bytes.NewReader(testBuffer)
+	assert.Error(t, bb.ReadInt64(&buf))
+
+	// Reached EOF during read (partial)
+	bb = NewReaderBytes(testBuffer, binary.LittleEndian)
+	bb.source.Read(make([]byte, len(testBuffer)-7))
+	assert.Error(t, bb.ReadInt64(&buf))
+}
+
 func TestReadFloat32(t *testing.T) {
 	t.Parallel()
 	f32 := float32(0)
@@ -356,6 +522,96 @@ func TestReadFloat64Error(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestReadUvarint(t *testing.T) {
+	t.Parallel()
+	values := []uint64{0, 1, 127, 128, 300, 16384, math.MaxUint32, math.MaxUint64}
+	buf := []byte{}
+	tmp := make([]byte, binary.MaxVarintLen64)
+	for _, v := range values {
+		buf = append(buf, tmp[:binary.PutUvarint(tmp, v)]...)
+	}
+	bb := NewReaderBytes(buf, binary.LittleEndian)
+	ux := uint64(0)
+	for _, expected := range values {
+		assert.NoError(t, bb.ReadUvarint(&ux))
+		assert.Equal(t, expected, ux)
+	}
+	assert.Equal(t, int64(len(buf)), bb.GetPosition())
+}
+
+func TestReadUvarintAfterPeek(t *testing.T) {
+	t.Parallel()
+	bb := NewReaderBytes([]byte{0xAC, 0x02, 0x01}, binary.LittleEndian)
+	assert.NoError(t, bb.Peek(make([]byte, 1)))
+	ux := uint64(0)
+	assert.NoError(t, bb.ReadUvarint(&ux))
+	assert.Equal(t, uint64(300), ux)
+	assert.Equal(t, int64(2), bb.GetPosition())
+}
+
+func TestReadUvarintError(t *testing.T) {
+	t.Parallel()
+	ux := uint64(0)
+
+	// nil reader
+	bb := Reader{}
+	assert.Error(t, bb.ReadUvarint(&ux))
+
+	// Reached EOF mid-varint
+	bb = NewReaderBytes([]byte{0x80, 0x80}, binary.LittleEndian)
+	assert.Error(t, bb.ReadUvarint(&ux))
+
+	// overflow: 10th byte carries more than one bit
+	buf := append([]byte{0x01, 0x02}, bytes.Repeat([]byte{0xFF}, 9)...)
+	buf = append(buf, 0x02)
+	bb = NewReaderBytes(buf, binary.LittleEndian)
+	assert.NoError(t, bb.Discard(2))
+	err := bb.ReadUvarint(&ux)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "offset 2")
+
+	// overflow: too many continuation bytes
+	bb = NewReaderBytes(bytes.Repeat([]byte{0x80}, 11), binary.LittleEndian)
+	assert.Error(t, bb.ReadUvarint(&ux))
+
+	// exceeds configured maximum length
+	bb = NewReaderBytes([]byte{0x80, 0x80, 0x01}, binary.LittleEndian)
+	bb.SetMaxVarintLen(2)
+	err = bb.ReadUvarint(&ux)
+	assert.True(t, errors.Is(err, ErrTooLong))
+	assert.Contains(t, err.Error(), "offset 0")
+}
+
+func TestReadVarint(t *testing.T) {
+	t.Parallel()
+	values := []int64{0, 1, -1, 63, -64, 64, -65, math.MaxInt64, math.MinInt64}
+	buf := []byte{}
+	tmp := make([]byte, binary.MaxVarintLen64)
+	for _, v := range values {
+		buf = append(buf, tmp[:binary.PutVarint(tmp, v)]...)
+	}
+	bb := NewReaderBytes(buf, binary.LittleEndian)
+	x := int64(0)
+	for _, expected := range values {
+		assert.NoError(t, bb.ReadVarint(&x))
+		assert.Equal(t, expected, x)
+	}
+	assert.Equal(t, int64(len(buf)), bb.GetPosition())
+}
+
+func TestReadVarintError(t *testing.T) {
+	t.Parallel()
+	x := int64(0)
+
+	// nil reader
+	bb := Reader{}
+	assert.Error(t, bb.ReadVarint(&x))
+
+	// Reached EOF mid-varint
+	bb = NewReaderBytes([]byte{0xFF}, binary.LittleEndian)
+	assert.Error(t, bb.ReadVarint(&x))
+}
+
 func TestPeek(t *testing.T) {
 	t.Parallel()
 	bb := NewReaderBytes(testBuffer, binary.LittleEndian)
@@ -478,6 +734,193 @@ func TestPeekError(t *testing.T) {
 	assert.Error(t, bb.Peek(buf))
 }
 
+func TestReadAfterPeek(t *testing.T) {
+	t.Parallel()
+	bb := NewReaderBytes(testBuffer, binary.LittleEndian)
+	assert.NoError(t, bb.Peek(make([]byte, 4)))
+
+	// `Read` returns the peeked bytes first
+	buf := make([]byte, 3)
+	n, err := bb.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []byte("123"), buf)
+	assert.Equal(t, int64(3), bb.GetPosition())
+	n, err = bb.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, byte('4'), buf[0])
+	n, err = bb.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []byte("567"), buf)
+	assert.Equal(t, int64(7), bb.GetPosition())
+
+	// `Discard` consumes peeked bytes before reading
+	assert.NoError(t, bb.Peek(make([]byte, 5)))
+	assert.NoError(t, bb.Discard(3))
+	assert.Equal(t, int64(10), bb.GetPosition())
+	assert.NoError(t, bb.Discard(4))
+	assert.NoError(t, bb.ReadBytes(buf))
+	assert.Equal(t, []byte("efg"), buf)
+	assert.Equal(t, int64(17), bb.GetPosition())
+
+	// peeked bytes followed by EOF are an unexpected EOF
+	bb = NewReaderBytes([]byte{1, 2}, nil)
+	assert.NoError(t, bb.Peek(make([]byte, 1)))
+	assert.True(t, errors.Is(bb.ReadBytes(buf), io.ErrUnexpectedEOF))
+	bb = NewReaderBytes([]byte{1, 2}, nil)
+	assert.NoError(t, bb.Peek(make([]byte, 1)))
+	assert.True(t, errors.Is(bb.Peek(buf), io.ErrUnexpectedEOF))
+}
+
+func TestPeekLimit(t *testing.T) {
+	t.Parallel()
+	bb := NewReader(blackHole, nil)
+	assert.NoError(t, bb.Peek(make([]byte, DefaultReadAheadLimit)))
+	assert.True(t, errors.Is(bb.Peek(make([]byte, DefaultReadAheadLimit+1)), ErrTooLong))
+
+	bb.SetReadAheadLimit(16)
+	assert.True(t, errors.Is(bb.Peek(make([]byte, 17)), ErrTooLong))
+	bb.SetReadAheadLimit(-1)
+	assert.NoError(t, bb.Peek(make([]byte, DefaultReadAheadLimit)))
+
+	// sub-readers inherit the limit
+	bb = NewReader(blackHole, nil)
+	bb.SetReadAheadLimit(8)
+	sub, err := bb.SubReader(100)
+	assert.NoError(t, err)
+	assert.True(t, errors.Is(sub.Peek(make([]byte, 9)), ErrTooLong))
+	assert.NoError(t, sub.Peek(make([]byte, 8)))
+
+	// repeated peeks and reads do not grow the buffer beyond the limit
+	bb = NewReaderBytes(bytes.Repeat(testBuffer, 1000), nil)
+	bb.SetReadAheadLimit(100)
+	buf := make([]byte, 33)
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, bb.Peek(buf[:i%34]))
+		assert.NoError(t, bb.ReadBytes(buf[:i%7]))
+	}
+	assert.True(t, cap(bb.buf) <= 100)
+}
+
+func TestPeekNoOverRead(t *testing.T) {
+	t.Parallel()
+	src := bytes.NewReader(testBuffer)
+	bb := NewReader(src, nil)
+	assert.NoError(t, bb.Peek(make([]byte, 3)))
+	assert.NoError(t, bb.Peek(make([]byte, 5)))
+	assert.NoError(t, bb.ReadBytes(make([]byte, 2)))
+	assert.NoError(t, bb.Peek(make([]byte, 4)))
+	assert.Equal(t, len(testBuffer)-6, src.Len())
+}
+
+func TestNext(t *testing.T) {
+	t.Parallel()
+	bb := NewReader(bytes.NewBufferString(string(testBuffer)), nil)
+	p, err := bb.Next(3)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("123"), p)
+	assert.Equal(t, 3, cap(p))
+	assert.Equal(t, int64(3), bb.GetPosition())
+	assert.NoError(t, bb.Peek(make([]byte, 2)))
+	p, err = bb.Next(4)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("4567"), p)
+	p, err = bb.Next(0)
+	assert.NoError(t, err)
+	assert.Empty(t, p)
+	buf := make([]byte, 3)
+	assert.NoError(t, bb.ReadBytes(buf))
+	assert.Equal(t, []byte("890"), buf)
+	assert.Equal(t, int64(10), bb.GetPosition())
+}
+
+func TestNextBytes(t *testing.T) {
+	t.Parallel()
+	src := []byte(string(testBuffer))
+	bb := NewReaderBytes(src, nil)
+	p, err := bb.Next(3)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("123"), p)
+	assert.True(t, &p[0] == &src[0])
+	assert.Equal(t, 3, cap(p))
+
+	// buffered bytes are skipped rather than copied
+	assert.NoError(t, bb.Peek(make([]byte, 2)))
+	p, err = bb.Next(4)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("4567"), p)
+	assert.True(t, &p[0] == &src[3])
+	assert.NoError(t, bb.Peek(make([]byte, 4)))
+	p, err = bb.Next(2)
+	assert.NoError(t, err)
+	assert.True(t, &p[0] == &src[7])
+	var c byte
+	assert.NoError(t, bb.ReadByte(&c))
+	assert.Equal(t, byte('0'), c)
+	assert.Equal(t, int64(10), bb.GetPosition())
+
+	// sub-readers of a byte slice are zero-copy too
+	sub, err := bb.SubReader(4)
+	assert.NoError(t, err)
+	p, err = sub.Next(3)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("abc"), p)
+	assert.True(t, &p[0] == &src[10])
+	assert.True(t, errors.Is(sub.ReadBytes(make([]byte, 2)), ErrExceededContainer))
+	assert.NoError(t, sub.SkipRemaining())
+	assert.Equal(t, int64(14), bb.GetPosition())
+
+	// and remain consistent with `Seek`
+	assert.NoError(t, bb.SetPosition(1))
+	p, err = bb.Next(2)
+	assert.NoError(t, err)
+	assert.True(t, &p[0] == &src[1])
+	assert.Equal(t, int64(3), bb.GetPosition())
+
+	// `Reset` to another source disables the fast path
+	bb.Reset(bytes.NewReader(src), nil)
+	p, err = bb.Next(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("12"), p)
+	assert.False(t, &p[0] == &src[0])
+}
+
+func TestNextError(t *testing.T) {
+	t.Parallel()
+	for _, bb := range []Reader{
+		NewReaderBytes([]byte{1, 2, 3}, nil),
+		NewReader(bytes.NewBuffer([]byte{1, 2, 3}), nil),
+	} {
+		_, err := bb.Next(-1)
+		assert.True(t, errors.Is(err, ErrNegativeLength))
+		_, err = bb.Next(4)
+		assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+		p, err := bb.Next(3)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{1, 2, 3}, p)
+		_, err = bb.Next(1)
+		assert.True(t, errors.Is(err, io.EOF))
+	}
+	bb := NewReader(blackHole, nil)
+	bb.SetReadAheadLimit(8)
+	_, err := bb.Next(9)
+	assert.True(t, errors.Is(err, ErrTooLong))
+	bb = Reader{}
+	_, err = bb.Next(1)
+	assert.True(t, errors.Is(err, ErrNilSource))
+}
+
+func TestNextAllocs(t *testing.T) {
+	src := make([]byte, 1<<16)
+	bb := NewReaderBytes(src, nil)
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = bb.Next(64)
+	})
+	assert.Equal(t, 0.0, allocs)
+}
+
 func TestDiscard(t *testing.T) {
 	t.Parallel()
 	bb := NewReader(blackHole, binary.LittleEndian)
@@ -517,81 +960,402 @@ func TestDiscardError(t *testing.T) {
 	assert.Error(t, err)
 }
 
-func TestReaderReset(t *testing.T) {
+func TestSeek(t *testing.T) {
 	t.Parallel()
-	// Big Endian reader at position 10
-	bb := NewReaderBytes(testBuffer, binary.BigEndian)
-	assert.NoError(t, bb.Discard(10))
-	assert.Equal(t, int64(10), bb.GetPosition())
+	bb := NewReaderBytes(testBuffer, binary.LittleEndian)
+	buf := make([]byte, 4)
 
-	// Reset to Little Endian
-	buf2 := []byte("9876543210")
-	bb.Reset(bytes.NewReader(buf2), binary.LittleEndian)
-	assert.Equal(t, int64(0), bb.GetPosition())
-	assert.Equal(t, binary.LittleEndian, bb.GetByteOrder())
-	assert.NoError(t, bb.Discard(4))
-	assert.Equal(t, int64(4), bb.GetPosition())
-}
+	// rewind to start
+	assert.NoError(t, bb.ReadBytes(buf))
+	pos, err := bb.Seek(0, io.SeekStart)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), pos)
+	assert.NoError(t, bb.ReadBytes(buf))
+	assert.Equal(t, []byte("1234"), buf)
 
-/*
-===============================================================================
-    Writer
-===============================================================================
-*/
+	// relative to end
+	pos, err = bb.Seek(-2, io.SeekEnd)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(testBuffer)-2), pos)
+	assert.NoError(t, bb.ReadBytes(buf[:2]))
+	assert.Equal(t, []byte("yz"), buf[:2])
 
-func TestNewWriter(t *testing.T) {
-	t.Parallel()
-	w := bytes.NewBuffer(testBuffer)
-	bw := NewWriter(w, binary.LittleEndian)
-	assert.Equal(t, int64(0), bw.GetPosition())
-}
+	// report current position
+	pos, err = bb.Seek(0, io.SeekCurrent)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(testBuffer)), pos)
 
-func TestWriteByte(t *testing.T) {
-	t.Parallel()
-	w := bytes.NewBuffer([]byte{})
-	bw := NewWriter(w, binary.LittleEndian)
-	// put all of `testBuffer` into bw byte-by-byte
-	for _, c := range testBuffer {
-		err := bw.WriteByte(c)
-		assert.NoError(t, err)
-	}
-	assert.Equal(t, int64(len(testBuffer)), bw.GetPosition())
-	assert.Equal(t, testBuffer, w.Bytes())
+	assert.NoError(t, bb.SetPosition(10))
+	assert.Equal(t, int64(10), bb.GetPosition())
+	assert.NoError(t, bb.ReadBytes(buf[:1]))
+	assert.Equal(t, []byte("a"), buf[:1])
 }
 
-func TestWriteByteError(t *testing.T) {
+func TestSeekAfterPeek(t *testing.T) {
 	t.Parallel()
-	// nil writer
-	bw := Writer{}
-	bw.bo = binary.LittleEndian
-	assert.Error(t, bw.WriteByte(0xFF))
+	bb := NewReaderBytes(testBuffer, binary.LittleEndian)
+	buf := make([]byte, 2)
 
-	// writer error
-	bw = NewWriter(errRW, binary.LittleEndian)
-	assert.Error(t, bw.WriteByte(0xFF))
-}
+	// forward, within peeked bytes
+	assert.NoError(t, bb.Peek(make([]byte, 8)))
+	pos, err := bb.Seek(3, io.SeekCurrent)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), pos)
+	assert.NoError(t, bb.ReadBytes(buf))
+	assert.Equal(t, []byte("45"), buf)
+	assert.Equal(t, int64(5), bb.GetPosition())
 
-func TestWrite(t *testing.T) {
-	t.Parallel()
-	w := bytes.NewBuffer([]byte{})
-	bw := NewWriter(w, binary.LittleEndian)
-	// put all of `testBuffer` into bw
-	nwrite, err := bw.Write(testBuffer)
+	// forward, beyond peeked bytes
+	assert.NoError(t, bb.Peek(make([]byte, 2)))
+	pos, err = bb.Seek(4, io.SeekCurrent)
 	assert.NoError(t, err)
-	assert.Equal(t, len(testBuffer), nwrite)
-	assert.Equal(t, int64(len(testBuffer)), bw.GetPosition())
-	assert.Equal(t, testBuffer, w.Bytes())
+	assert.Equal(t, int64(9), pos)
+	assert.NoError(t, bb.ReadBytes(buf))
+	assert.Equal(t, []byte("0a"), buf)
 
-	nwrite, err = bw.Write([]byte{})
+	// backward, discarding peeked bytes
+	assert.NoError(t, bb.Peek(make([]byte, 4)))
+	pos, err = bb.Seek(-3, io.SeekCurrent)
 	assert.NoError(t, err)
-	assert.Equal(t, 0, nwrite)
-	assert.Equal(t, int64(len(testBuffer)), bw.GetPosition())
-	assert.Equal(t, testBuffer, w.Bytes())
+	assert.Equal(t, int64(8), pos)
+	assert.NoError(t, bb.ReadBytes(buf))
+	assert.Equal(t, []byte("90"), buf)
+
+	// absolute, discarding peeked bytes
+	assert.NoError(t, bb.Peek(make([]byte, 4)))
+	assert.NoError(t, bb.SetPosition(1))
+	assert.NoError(t, bb.ReadBytes(buf))
+	assert.Equal(t, []byte("23"), buf)
+	assert.Equal(t, int64(3), bb.GetPosition())
 }
 
-func TestWriteBytes(t *testing.T) {
+func TestSeekError(t *testing.T) {
 	t.Parallel()
-	w := bytes.NewBuffer([]byte{})
+	// nil reader
+	bb := Reader{}
+	_, err := bb.Seek(0, io.SeekStart)
+	assert.Error(t, err)
+
+	// source cannot seek
+	bb = NewReader(blackHole, binary.LittleEndian)
+	_, err = bb.Seek(4, io.SeekStart)
+	assert.Error(t, err)
+	assert.Error(t, bb.SetPosition(4))
+	// but the current position may still be queried
+	pos, err := bb.Seek(0, io.SeekCurrent)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), pos)
+
+	// source rejects the seek
+	bb = NewReaderBytes(testBuffer, binary.LittleEndian)
+	assert.NoError(t, bb.Discard(2))
+	_, err = bb.Seek(-4, io.SeekCurrent)
+	assert.Error(t, err)
+	assert.Equal(t, int64(2), bb.GetPosition())
+}
+
+func TestSubReader(t *testing.T) {
+	t.Parallel()
+	bb := NewReaderBytes(testBuffer, binary.BigEndian)
+	assert.Equal(t, int64(-1), bb.Remaining())
+	assert.NoError(t, bb.Discard(2))
+
+	sub, err := bb.SubReader(6)
+	assert.NoError(t, err)
+	assert.Equal(t, binary.BigEndian, sub.GetByteOrder())
+	assert.Equal(t, int64(6), sub.Remaining())
+	assert.Equal(t, int64(0), sub.GetPosition())
+	assert.Equal(t, int64(2), sub.GetAbsolutePosition())
+
+	ui16 := uint16(0)
+	assert.NoError(t, sub.ReadUint16(&ui16))
+	assert.Equal(t, uint16(0x3334), ui16)
+	assert.Equal(t, int64(4), sub.Remaining())
+	assert.Equal(t, int64(2), sub.GetPosition())
+	assert.Equal(t, int64(4), sub.GetAbsolutePosition())
+	assert.Equal(t, int64(4), bb.GetPosition())
+
+	// nested container
+	nested, err := sub.SubReader(2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), nested.GetAbsolutePosition())
+	buf := make([]byte, 2)
+	assert.NoError(t, nested.ReadBytes(buf))
+	assert.Equal(t, []byte("56"), buf)
+	assert.Equal(t, int64(0), nested.Remaining())
+	assert.True(t, errors.Is(nested.ReadBytes(buf[:1]), ErrExceededContainer))
+	assert.NoError(t, nested.SkipRemaining())
+
+	// skip what the sub-reader did not consume
+	assert.Equal(t, int64(2), sub.Remaining())
+	assert.NoError(t, sub.SkipRemaining())
+	assert.Equal(t, int64(0), sub.Remaining())
+	assert.Equal(t, int64(6), sub.GetPosition())
+	assert.Equal(t, int64(8), bb.GetPosition())
+	assert.NoError(t, bb.ReadBytes(buf))
+	assert.Equal(t, []byte("90"), buf)
+}
+
+func TestSubReaderExceeded(t *testing.T) {
+	t.Parallel()
+	bb := NewReaderBytes(testBuffer, binary.LittleEndian)
+	sub, err := bb.SubReader(3)
+	assert.NoError(t, err)
+
+	// does not read into the sibling
+	ui32 := uint32(0)
+	assert.True(t, errors.Is(sub.ReadUint32(&ui32), ErrExceededContainer))
+	assert.Equal(t, int64(3), bb.GetPosition())
+	assert.True(t, errors.Is(sub.Peek(make([]byte, 1)), ErrExceededContainer))
+
+	// peeked bytes are consumed from the parent, but still skipped correctly
+	sub, err = bb.SubReader(4)
+	assert.NoError(t, err)
+	assert.NoError(t, sub.Peek(make([]byte, 3)))
+	assert.NoError(t, sub.SkipRemaining())
+	assert.Equal(t, int64(7), bb.GetPosition())
+
+	// nested container larger than its parent
+	sub, err = bb.SubReader(4)
+	assert.NoError(t, err)
+	_, err = sub.SubReader(5)
+	assert.True(t, errors.Is(err, ErrExceededContainer))
+}
+
+func TestSubReaderIOReader(t *testing.T) {
+	t.Parallel()
+	// `Read` ends a container with `io.EOF`, as `io.Reader` requires
+	for _, bb := range []Reader{
+		NewReaderBytes([]byte("abcdefgh"), binary.LittleEndian),
+		NewReader(struct{ io.Reader }{bytes.NewReader([]byte("abcdefgh"))}, binary.LittleEndian),
+	} {
+		sub, err := bb.SubReader(4)
+		assert.NoError(t, err)
+		p, err := ioutil.ReadAll(&sub)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("abcd"), p)
+		n, err := sub.Read(make([]byte, 1))
+		assert.Equal(t, 0, n)
+		assert.Equal(t, io.EOF, err)
+		assert.Equal(t, int64(4), bb.GetPosition())
+
+		sub, err = bb.SubReader(3)
+		assert.NoError(t, err)
+		out := bytes.NewBuffer([]byte{})
+		m, err := io.Copy(out, &sub)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), m)
+		assert.Equal(t, "efg", out.String())
+
+		// whereas typed reads report the bound
+		var c byte
+		assert.True(t, errors.Is(sub.ReadByte(&c), ErrExceededContainer))
+		assert.True(t, errors.Is(sub.Discard(1), ErrExceededContainer))
+		assert.NoError(t, bb.ReadByte(&c))
+		assert.Equal(t, byte('h'), c)
+	}
+}
+
+func TestSubReaderError(t *testing.T) {
+	t.Parallel()
+	// nil reader
+	bb := Reader{}
+	_, err := bb.SubReader(4)
+	assert.Error(t, err)
+
+	// negative length
+	bb = NewReaderBytes(testBuffer, binary.LittleEndian)
+	_, err = bb.SubReader(-1)
+	assert.Error(t, err)
+
+	// not a sub-reader
+	assert.Error(t, bb.SkipRemaining())
+
+	// parent reaches EOF
+	sub, err := bb.SubReader(int64(len(testBuffer) + 10))
+	assert.NoError(t, err)
+	assert.Error(t, sub.SkipRemaining())
+}
+
+func TestReaderReset(t *testing.T) {
+	t.Parallel()
+	// Big Endian reader at position 10
+	bb := NewReaderBytes(testBuffer, binary.BigEndian)
+	assert.NoError(t, bb.Discard(10))
+	assert.Equal(t, int64(10), bb.GetPosition())
+
+	// Reset to Little Endian
+	buf2 := []byte("9876543210")
+	bb.Reset(bytes.NewReader(buf2), binary.LittleEndian)
+	assert.Equal(t, int64(0), bb.GetPosition())
+	assert.Equal(t, binary.LittleEndian, bb.GetByteOrder())
+	assert.NoError(t, bb.Discard(4))
+	assert.Equal(t, int64(4), bb.GetPosition())
+}
+
+/*
+===============================================================================
+    Reader: Sticky Errors
+===============================================================================
+*/
+
+func TestStickyRead(t *testing.T) {
+	t.Parallel()
+	buf := []byte{
+		0x01,
+		0x02, 0x03,
+		0x04, 0x05, 0x06, 0x07,
+		0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F,
+		0xFF,
+		0xFE, 0xFF,
+		0xFC, 0xFF, 0xFF, 0xFF,
+		0xF8, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x00, 0xC0, 0x3F,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xF8, 0xBF,
+		0xAC, 0x02,
+		0x03,
+		'a', 'b', 'c',
+		0x00, 0x00,
+		0x42,
+	}
+	bb := NewReaderBytes(buf, binary.LittleEndian)
+	assert.Equal(t, byte(0x01), bb.Byte())
+	assert.Equal(t, uint16(0x0302), bb.Uint16())
+	assert.Equal(t, uint32(0x07060504), bb.Uint32())
+	assert.Equal(t, uint64(0x0F0E0D0C0B0A0908), bb.Uint64())
+	assert.Equal(t, int8(-1), bb.Int8())
+	assert.Equal(t, int16(-2), bb.Int16())
+	assert.Equal(t, int32(-4), bb.Int32())
+	assert.Equal(t, int64(-8), bb.Int64())
+	assert.Equal(t, float32(1.5), bb.Float32())
+	assert.Equal(t, float64(-1.5), bb.Float64())
+	assert.Equal(t, uint64(300), bb.Uvarint())
+	assert.Equal(t, int64(-2), bb.Varint())
+	tmp := make([]byte, 3)
+	bb.Fill(tmp)
+	assert.Equal(t, []byte("abc"), tmp)
+	bb.Skip(2)
+	assert.Equal(t, byte(0x42), bb.Byte())
+	assert.NoError(t, bb.Err())
+	assert.Equal(t, int64(len(buf)), bb.GetPosition())
+}
+
+func TestStickyError(t *testing.T) {
+	t.Parallel()
+	bb := NewReaderBytes([]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06}, binary.BigEndian)
+	assert.Equal(t, uint16(0x0102), bb.Uint16())
+	// partial read latches the error, and reports where the read began
+	assert.Equal(t, uint64(0), bb.Uint64())
+	assert.Error(t, bb.Err())
+	assert.Equal(t, io.ErrUnexpectedEOF, errors.Unwrap(bb.Err()))
+	assert.Contains(t, bb.Err().Error(), "offset 2")
+	pos := bb.GetPosition()
+
+	// subsequent reads are no-ops, and do not replace the first error
+	first := bb.Err()
+	assert.Equal(t, byte(0), bb.Byte())
+	assert.Equal(t, uint32(0), bb.Uint32())
+	assert.Equal(t, float64(0), bb.Float64())
+	tmp := []byte{0xAA}
+	bb.Fill(tmp)
+	assert.Equal(t, []byte{0xAA}, tmp)
+	bb.Skip(1)
+	assert.Equal(t, pos, bb.GetPosition())
+	assert.Equal(t, first, bb.Err())
+
+	// `Read*` methods are unaffected
+	bb = NewReaderBytes([]byte{0x01, 0x02}, binary.BigEndian)
+	bb.Skip(3)
+	assert.Error(t, bb.Err())
+	bb.Reset(bytes.NewReader([]byte{0x01, 0x02}), binary.BigEndian)
+	assert.NoError(t, bb.Err())
+	v := uint16(0)
+	assert.NoError(t, bb.ReadUint16(&v))
+	assert.Equal(t, uint16(0x0102), v)
+
+	// missing byte order
+	bb = NewReaderBytes([]byte{0x01, 0x02}, nil)
+	assert.Equal(t, byte(0x01), bb.Byte())
+	assert.Equal(t, int16(0), bb.Int16())
+	assert.Error(t, bb.Err())
+	assert.Contains(t, bb.Err().Error(), "offset 1")
+
+	// nil reader
+	bb = Reader{}
+	assert.Equal(t, uint64(0), bb.Uvarint())
+	assert.Error(t, bb.Err())
+}
+
+func TestStickyReset(t *testing.T) {
+	t.Parallel()
+	bb := NewReaderBytes([]byte{}, binary.LittleEndian)
+	assert.Equal(t, int64(0), bb.Varint())
+	assert.Error(t, bb.Err())
+	bb.Reset(bytes.NewReader([]byte{0x00, 0x00, 0x80, 0x3F}), binary.LittleEndian)
+	assert.NoError(t, bb.Err())
+	assert.Equal(t, float32(1), bb.Float32())
+	assert.NoError(t, bb.Err())
+}
+
+/*
+===============================================================================
+    Writer
+===============================================================================
+*/
+
+func TestNewWriter(t *testing.T) {
+	t.Parallel()
+	w := bytes.NewBuffer(testBuffer)
+	bw := NewWriter(w, binary.LittleEndian)
+	assert.Equal(t, int64(0), bw.GetPosition())
+}
+
+func TestWriteByte(t *testing.T) {
+	t.Parallel()
+	w := bytes.NewBuffer([]byte{})
+	bw := NewWriter(w, binary.LittleEndian)
+	// put all of `testBuffer` into bw byte-by-byte
+	for _, c := range testBuffer {
+		err := bw.WriteByte(c)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int64(len(testBuffer)), bw.GetPosition())
+	assert.Equal(t, testBuffer, w.Bytes())
+}
+
+func TestWriteByteError(t *testing.T) {
+	t.Parallel()
+	// nil writer
+	bw := Writer{}
+	bw.bo = binary.LittleEndian
+	assert.Error(t, bw.WriteByte(0xFF))
+
+	// writer error
+	bw = NewWriter(errRW, binary.LittleEndian)
+	assert.Error(t, bw.WriteByte(0xFF))
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+	w := bytes.NewBuffer([]byte{})
+	bw := NewWriter(w, binary.LittleEndian)
+	// put all of `testBuffer` into bw
+	nwrite, err := bw.Write(testBuffer)
+	assert.NoError(t, err)
+	assert.Equal(t, len(testBuffer), nwrite)
+	assert.Equal(t, int64(len(testBuffer)), bw.GetPosition())
+	assert.Equal(t, testBuffer, w.Bytes())
+
+	nwrite, err = bw.Write([]byte{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, nwrite)
+	assert.Equal(t, int64(len(testBuffer)), bw.GetPosition())
+	assert.Equal(t, testBuffer, w.Bytes())
+}
+
+func TestWriteBytes(t *testing.T) {
+	t.Parallel()
+	w := bytes.NewBuffer([]byte{})
 	bw := NewWriter(w, binary.LittleEndian)
 	// put all of `testBuffer` into bw
 	err := bw.WriteBytes(testBuffer)
@@ -728,181 +1492,3168 @@ func TestWriteUint64Error(t *testing.T) {
 	assert.Error(t, bw.WriteUint64(uint64(1234)))
 }
 
-func TestWriteFloat32(t *testing.T) {
+func TestWriteInt8(t *testing.T) {
+	t.Parallel()
+	w := bytes.NewBuffer([]byte{})
+	bw := NewWriter(w, binary.LittleEndian)
+
+	assert.NoError(t, bw.WriteInt8(int8(-1)))
+	assert.NoError(t, bw.WriteInt8(int8(127)))
+	assert.NoError(t, bw.WriteInt8(int8(-128)))
+	assert.Equal(t, []byte{0xFF, 0x7F, 0x80}, w.Bytes())
+	assert.Equal(t, int64(3), bw.GetPosition())
+}
+
+func TestWriteInt8Error(t *testing.T) {
+	t.Parallel()
+	// nil writer
+	bw := Writer{}
+	bw.bo = binary.LittleEndian
+	assert.Error(t, bw.WriteInt8(int8(-1)))
+
+	// writer error
+	bw = NewWriter(errRW, binary.LittleEndian)
+	assert.Error(t, bw.WriteInt8(int8(-1)))
+}
+
+func TestWriteInt16(t *testing.T) {
 	t.Parallel()
 	// Little Endian
 	w := bytes.NewBuffer([]byte{})
 	bw := NewWriter(w, binary.LittleEndian)
 
-	err := bw.WriteFloat32(float32(1234.5678))
+	err := bw.WriteInt16(int16(-1234))
 	assert.NoError(t, err)
-	assert.Equal(t, []byte{0x2B, 0x52, 0x9A, 0x44}, w.Bytes())
-	assert.Equal(t, int64(4), bw.GetPosition())
+	assert.Equal(t, []byte{0x2E, 0xFB}, w.Bytes())
+	assert.Equal(t, int64(2), bw.GetPosition())
 
 	// Big Endian
 	w = bytes.NewBuffer([]byte{})
 	bw = NewWriter(w, binary.BigEndian)
 
-	err = bw.WriteFloat32(float32(1234.5678))
+	err = bw.WriteInt16(int16(-1234))
 	assert.NoError(t, err)
-	assert.Equal(t, []byte{0x44, 0x9A, 0x52, 0x2B}, w.Bytes())
-	assert.Equal(t, int64(4), bw.GetPosition())
+	assert.Equal(t, []byte{0xFB, 0x2E}, w.Bytes())
+	assert.Equal(t, int64(2), bw.GetPosition())
 }
 
-func TestWriteFloat32Error(t *testing.T) {
+func TestWriteInt16Error(t *testing.T) {
 	t.Parallel()
 	// nil writer
 	bw := Writer{}
 	bw.bo = binary.LittleEndian
-	assert.Error(t, bw.WriteFloat32(float32(1234.5678)))
+	assert.Error(t, bw.WriteInt16(int16(-1234)))
 
 	// nil byte order
 	bw = Writer{}
 	bw.dest = bytes.NewBuffer([]byte{})
-	assert.Error(t, bw.WriteFloat32(float32(1234.5678)))
+	assert.Error(t, bw.WriteInt16(int16(-1234)))
 
 	// writer error
 	bw = NewWriter(errRW, binary.LittleEndian)
-	assert.Error(t, bw.WriteFloat32(float32(1234.5678)))
+	assert.Error(t, bw.WriteInt16(int16(-1234)))
 }
 
-func TestWriteFloat64(t *testing.T) {
+func TestWriteInt32(t *testing.T) {
 	t.Parallel()
 	// Little Endian
 	w := bytes.NewBuffer([]byte{})
 	bw := NewWriter(w, binary.LittleEndian)
 
-	err := bw.WriteFloat64(float64(1234.5678))
+	err := bw.WriteInt32(int32(-1234))
 	assert.NoError(t, err)
-	assert.Equal(t, []byte{0xAD, 0xFA, 0x5C, 0x6D, 0x45, 0x4A, 0x93, 0x40}, w.Bytes())
-	assert.Equal(t, int64(8), bw.GetPosition())
+	assert.Equal(t, []byte{0x2E, 0xFB, 0xFF, 0xFF}, w.Bytes())
+	assert.Equal(t, int64(4), bw.GetPosition())
 
 	// Big Endian
 	w = bytes.NewBuffer([]byte{})
 	bw = NewWriter(w, binary.BigEndian)
 
-	err = bw.WriteFloat64(float64(1234.5678))
+	err = bw.WriteInt32(int32(-1234))
 	assert.NoError(t, err)
-	assert.Equal(t, []byte{0x40, 0x93, 0x4A, 0x45, 0x6D, 0x5C, 0xFA, 0xAD}, w.Bytes())
-	assert.Equal(t, int64(8), bw.GetPosition())
+	assert.Equal(t, []byte{0xFF, 0xFF, 0xFB, 0x2E}, w.Bytes())
+	assert.Equal(t, int64(4), bw.GetPosition())
 }
 
-func TestWriteFloat64Error(t *testing.T) {
+func TestWriteInt32Error(t *testing.T) {
 	t.Parallel()
 	// nil writer
 	bw := Writer{}
 	bw.bo = binary.LittleEndian
-	assert.Error(t, bw.WriteFloat64(float64(1234.5678)))
+	assert.Error(t, bw.WriteInt32(int32(-1234)))
 
 	// nil byte order
 	bw = Writer{}
 	bw.dest = bytes.NewBuffer([]byte{})
-	assert.Error(t, bw.WriteFloat64(float64(1234.5678)))
+	assert.Error(t, bw.WriteInt32(int32(-1234)))
 
 	// writer error
 	bw = NewWriter(errRW, binary.LittleEndian)
-	assert.Error(t, bw.WriteFloat64(float64(1234.5678)))
+	assert.Error(t, bw.WriteInt32(int32(-1234)))
 }
 
-func TestZeroFill(t *testing.T) {
+func TestWriteInt64(t *testing.T) {
 	t.Parallel()
+	// Little Endian
 	w := bytes.NewBuffer([]byte{})
 	bw := NewWriter(w, binary.LittleEndian)
-	assert.NoError(t, bw.ZeroFill(64))
-	assert.Equal(t, make([]byte, 64), w.Bytes())
-	assert.Equal(t, int64(64), bw.GetPosition())
 
-	// ZeroFill zero length should shortcut
-	assert.NoError(t, bw.ZeroFill(0))
+	err := bw.WriteInt64(int64(-123456789))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xEB, 0x32, 0xA4, 0xF8, 0xFF, 0xFF, 0xFF, 0xFF}, w.Bytes())
+	assert.Equal(t, int64(8), bw.GetPosition())
 
-	// zero-fill (relatively) large amount of bytes
-	expectedPos := int64(64)
-	for _, v := range []int64{800, 1024, 1080, 4096, 8000} {
-		bw.ZeroFill(v)
-		expectedPos += v
-		assert.Equal(t, expectedPos, bw.GetPosition())
-	}
+	// Big Endian
+	w = bytes.NewBuffer([]byte{})
+	bw = NewWriter(w, binary.BigEndian)
+
+	err = bw.WriteInt64(int64(-123456789))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xF8, 0xA4, 0x32, 0xEB}, w.Bytes())
+	assert.Equal(t, int64(8), bw.GetPosition())
 }
 
-func TestZeroFillError(t *testing.T) {
+func TestWriteInt64Error(t *testing.T) {
 	t.Parallel()
 	// nil writer
 	bw := Writer{}
 	bw.bo = binary.LittleEndian
-	assert.Error(t, bw.ZeroFill(64))
+	assert.Error(t, bw.WriteInt64(int64(-1234)))
+
+	// nil byte order
+	bw = Writer{}
+	bw.dest = bytes.NewBuffer([]byte{})
+	assert.Error(t, bw.WriteInt64(int64(-1234)))
 
 	// writer error
 	bw = NewWriter(errRW, binary.LittleEndian)
-	assert.Error(t, bw.ZeroFill(64))
-
-	// writer error with large enough discard to cause chunking
-	err = bw.ZeroFill(4096)
-	assert.Error(t, err)
-
-	// ZeroFill with negative length
-	assert.Error(t, bw.ZeroFill(-1))
+	assert.Error(t, bw.WriteInt64(int64(-1234)))
 }
 
-func TestWriterReset(t *testing.T) {
+func TestWriteFloat32(t *testing.T) {
 	t.Parallel()
-	// Big Endian writer at position 10
+	// Little Endian
 	w := bytes.NewBuffer([]byte{})
-	bw := NewWriter(w, binary.BigEndian)
-	assert.NoError(t, bw.ZeroFill(10))
-	assert.Equal(t, int64(10), bw.GetPosition())
+	bw := NewWriter(w, binary.LittleEndian)
 
-	// Reset to Little Endian
-	w2 := bytes.NewBuffer([]byte{})
-	bw.Reset(w2, binary.LittleEndian)
-	assert.Equal(t, int64(0), bw.GetPosition())
-	assert.Equal(t, binary.LittleEndian, bw.GetByteOrder())
-	assert.NoError(t, bw.ZeroFill(4))
+	err := bw.WriteFloat32(float32(1234.5678))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x2B, 0x52, 0x9A, 0x44}, w.Bytes())
 	assert.Equal(t, int64(4), bw.GetPosition())
-}
-
-/*
-===============================================================================
-    baseBinary
-===============================================================================
-*/
+
+	// Big Endian
+	w = bytes.NewBuffer([]byte{})
+	bw = NewWriter(w, binary.BigEndian)
+
+	err = bw.WriteFloat32(float32(1234.5678))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x44, 0x9A, 0x52, 0x2B}, w.Bytes())
+	assert.Equal(t, int64(4), bw.GetPosition())
+}
+
+func TestWriteFloat32Error(t *testing.T) {
+	t.Parallel()
+	// nil writer
+	bw := Writer{}
+	bw.bo = binary.LittleEndian
+	assert.Error(t, bw.WriteFloat32(float32(1234.5678)))
+
+	// nil byte order
+	bw = Writer{}
+	bw.dest = bytes.NewBuffer([]byte{})
+	assert.Error(t, bw.WriteFloat32(float32(1234.5678)))
+
+	// writer error
+	bw = NewWriter(errRW, binary.LittleEndian)
+	assert.Error(t, bw.WriteFloat32(float32(1234.5678)))
+}
+
+func TestWriteFloat64(t *testing.T) {
+	t.Parallel()
+	// Little Endian
+	w := bytes.NewBuffer([]byte{})
+	bw := NewWriter(w, binary.LittleEndian)
+
+	err := bw.WriteFloat64(float64(1234.5678))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xAD, 0xFA, 0x5C, 0x6D, 0x45, 0x4A, 0x93, 0x40}, w.Bytes())
+	assert.Equal(t, int64(8), bw.GetPosition())
+
+	// Big Endian
+	w = bytes.NewBuffer([]byte{})
+	bw = NewWriter(w, binary.BigEndian)
+
+	err = bw.WriteFloat64(float64(1234.5678))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x40, 0x93, 0x4A, 0x45, 0x6D, 0x5C, 0xFA, 0xAD}, w.Bytes())
+	assert.Equal(t, int64(8), bw.GetPosition())
+}
+
+func TestWriteFloat64Error(t *testing.T) {
+	t.Parallel()
+	// nil writer
+	bw := Writer{}
+	bw.bo = binary.LittleEndian
+	assert.Error(t, bw.WriteFloat64(float64(1234.5678)))
+
+	// nil byte order
+	bw = Writer{}
+	bw.dest = bytes.NewBuffer([]byte{})
+	assert.Error(t, bw.WriteFloat64(float64(1234.5678)))
+
+	// writer error
+	bw = NewWriter(errRW, binary.LittleEndian)
+	assert.Error(t, bw.WriteFloat64(float64(1234.5678)))
+}
+
+func TestWriteUvarint(t *testing.T) {
+	t.Parallel()
+	w := bytes.NewBuffer([]byte{})
+	bw := NewWriter(w, binary.LittleEndian)
+
+	assert.NoError(t, bw.WriteUvarint(uint64(300)))
+	assert.Equal(t, []byte{0xAC, 0x02}, w.Bytes())
+	assert.Equal(t, int64(2), bw.GetPosition())
+
+	assert.NoError(t, bw.WriteUvarint(math.MaxUint64))
+	assert.Equal(t, int64(2+binary.MaxVarintLen64), bw.GetPosition())
+
+	// round-trip through the reader
+	br := NewReaderBytes(w.Bytes(), binary.LittleEndian)
+	ux := uint64(0)
+	assert.NoError(t, br.ReadUvarint(&ux))
+	assert.Equal(t, uint64(300), ux)
+	assert.NoError(t, br.ReadUvarint(&ux))
+	assert.Equal(t, uint64(math.MaxUint64), ux)
+}
+
+func TestWriteUvarintError(t *testing.T) {
+	t.Parallel()
+	// nil writer
+	bw := Writer{}
+	assert.Error(t, bw.WriteUvarint(1))
+
+	// writer error
+	bw = NewWriter(errRW, binary.LittleEndian)
+	assert.Error(t, bw.WriteUvarint(1))
+
+	// exceeds configured maximum length
+	w := bytes.NewBuffer([]byte{})
+	bw = NewWriter(w, binary.LittleEndian)
+	bw.SetMaxVarintLen(2)
+	assert.NoError(t, bw.WriteUvarint(16383))
+	assert.True(t, errors.Is(bw.WriteUvarint(16384), ErrTooLong))
+	assert.Equal(t, int64(2), bw.GetPosition())
+}
+
+func TestWriteVarint(t *testing.T) {
+	t.Parallel()
+	w := bytes.NewBuffer([]byte{})
+	bw := NewWriter(w, binary.LittleEndian)
+
+	assert.NoError(t, bw.WriteVarint(int64(-65)))
+	assert.Equal(t, []byte{0x81, 0x01}, w.Bytes())
+	assert.NoError(t, bw.WriteVarint(math.MinInt64))
+
+	// round-trip through the reader
+	br := NewReaderBytes(w.Bytes(), binary.LittleEndian)
+	x := int64(0)
+	assert.NoError(t, br.ReadVarint(&x))
+	assert.Equal(t, int64(-65), x)
+	assert.NoError(t, br.ReadVarint(&x))
+	assert.Equal(t, int64(math.MinInt64), x)
+}
+
+func TestWriteVarintError(t *testing.T) {
+	t.Parallel()
+	// nil writer
+	bw := Writer{}
+	assert.Error(t, bw.WriteVarint(-1))
+
+	// writer error
+	bw = NewWriter(errRW, binary.LittleEndian)
+	assert.Error(t, bw.WriteVarint(-1))
+
+	// exceeds configured maximum length
+	bw = NewWriter(bytes.NewBuffer([]byte{}), binary.LittleEndian)
+	bw.SetMaxVarintLen(1)
+	assert.True(t, errors.Is(bw.WriteVarint(-65), ErrTooLong))
+}
+
+func TestZeroFill(t *testing.T) {
+	t.Parallel()
+	w := bytes.NewBuffer([]byte{})
+	bw := NewWriter(w, binary.LittleEndian)
+	assert.NoError(t, bw.ZeroFill(64))
+	assert.Equal(t, make([]byte, 64), w.Bytes())
+	assert.Equal(t, int64(64), bw.GetPosition())
+
+	// ZeroFill zero length should shortcut
+	assert.NoError(t, bw.ZeroFill(0))
+
+	// zero-fill (relatively) large amount of bytes
+	expectedPos := int64(64)
+	for _, v := range []int64{800, 1024, 1080, 4096, 8000} {
+		bw.ZeroFill(v)
+		expectedPos += v
+		assert.Equal(t, expectedPos, bw.GetPosition())
+	}
+}
+
+func TestZeroFillError(t *testing.T) {
+	t.Parallel()
+	// nil writer
+	bw := Writer{}
+	bw.bo = binary.LittleEndian
+	assert.Error(t, bw.ZeroFill(64))
+
+	// writer error
+	bw = NewWriter(errRW, binary.LittleEndian)
+	assert.Error(t, bw.ZeroFill(64))
+
+	// writer error with large enough discard to cause chunking
+	err = bw.ZeroFill(4096)
+	assert.Error(t, err)
+
+	// ZeroFill with negative length
+	assert.Error(t, bw.ZeroFill(-1))
+}
+
+func TestWriterReset(t *testing.T) {
+	t.Parallel()
+	// Big Endian writer at position 10
+	w := bytes.NewBuffer([]byte{})
+	bw := NewWriter(w, binary.BigEndian)
+	assert.NoError(t, bw.ZeroFill(10))
+	assert.Equal(t, int64(10), bw.GetPosition())
+
+	// Reset to Little Endian
+	w2 := bytes.NewBuffer([]byte{})
+	bw.Reset(w2, binary.LittleEndian)
+	assert.Equal(t, int64(0), bw.GetPosition())
+	assert.Equal(t, binary.LittleEndian, bw.GetByteOrder())
+	assert.NoError(t, bw.ZeroFill(4))
+	assert.Equal(t, int64(4), bw.GetPosition())
+}
+
+/*
+===============================================================================
+    Structs
+===============================================================================
+*/
+
+type testStructInner struct {
+	A int16
+	B [2]uint8
+}
+
+type testStruct struct {
+	Magic   [4]byte
+	Version uint16 `bin:"big"`
+	Flags   uint8
+	_       [1]byte
+	Offset  int32 `bin:"le"`
+	Scale   float32
+	Ratio   float64 `bin:"skip=2"`
+	Valid   bool
+	Inner   testStructInner
+	Pairs   [2]testStructInner
+	Count   uint8
+	Items   []uint16 `bin:"len=Count"`
+	NameLen int64
+	Name    string `bin:"len=NameLen"`
+	DataLen uint16
+	Data    []byte `bin:"len=DataLen"`
+	Ignored int    `bin:"-"`
+	private uint32
+}
+
+var testStructBytes = []byte{
+	'B', 'I', 'N', '!', // Magic
+	0x01, 0x02, // Version (big endian)
+	0x80,                   // Flags
+	0xEE,                   // padding
+	0xFE, 0xFF, 0xFF, 0xFF, // Offset (little endian)
+	0x42, 0xf6, 0xe9, 0x79, // Scale
+	0x00, 0x00, // skip
+	0x40, 0x5E, 0xDD, 0x2F, 0x1A, 0x9F, 0xBE, 0x77, // Ratio
+	0x01,                   // Valid
+	0xFF, 0xFF, 0x01, 0x02, // Inner
+	0x00, 0x01, 0x03, 0x04, 0x00, 0x02, 0x05, 0x06, // Pairs
+	0x02,                   // Count
+	0x00, 0x0A, 0x00, 0x0B, // Items
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, // NameLen
+	'a', 'b', 'c', // Name
+	0x00, 0x01, // DataLen
+	0x99, // Data
+}
+
+var testStructValue = testStruct{
+	Magic:   [4]byte{'B', 'I', 'N', '!'},
+	Version: 0x0102,
+	Flags:   0x80,
+	Offset:  -2,
+	Scale:   123.456,
+	Ratio:   123.456,
+	Valid:   true,
+	Inner:   testStructInner{A: -1, B: [2]uint8{1, 2}},
+	Pairs:   [2]testStructInner{{A: 1, B: [2]uint8{3, 4}}, {A: 2, B: [2]uint8{5, 6}}},
+	Count:   2,
+	Items:   []uint16{0x0A, 0x0B},
+	NameLen: 3,
+	Name:    "abc",
+	DataLen: 1,
+	Data:    []byte{0x99},
+}
+
+func TestReadStruct(t *testing.T) {
+	t.Parallel()
+	bb := NewReaderBytes(testStructBytes, binary.BigEndian)
+	v := testStruct{Ignored: 7, private: 9}
+	assert.NoError(t, bb.ReadStruct(&v))
+	expected := testStructValue
+	expected.Ignored = 7
+	expected.private = 9
+	assert.Equal(t, expected, v)
+	assert.Equal(t, int64(len(testStructBytes)), bb.GetPosition())
+	// per-field byte orders do not leak
+	assert.Equal(t, binary.BigEndian, bb.GetByteOrder())
+}
+
+func TestReadStructReusesSlices(t *testing.T) {
+	t.Parallel()
+	bb := NewReaderBytes(testStructBytes, binary.BigEndian)
+	items := make([]uint16, 0, 8)
+	v := testStruct{Items: items}
+	assert.NoError(t, bb.ReadStruct(&v))
+	assert.Equal(t, []uint16{0x0A, 0x0B}, v.Items)
+	assert.Equal(t, 8, cap(v.Items))
+}
+
+func TestReadStructError(t *testing.T) {
+	t.Parallel()
+	v := testStruct{}
+
+	// nil reader
+	bb := Reader{}
+	bb.bo = binary.LittleEndian
+	assert.Error(t, bb.ReadStruct(&v))
+
+	// nil byte order
+	bb = Reader{}
+	bb.source = bytes.NewReader(testStructBytes)
+	assert.Error(t, bb.ReadStruct(&v))
+
+	// not a pointer to a struct
+	bb = NewReaderBytes(testStructBytes, binary.BigEndian)
+	assert.Error(t, bb.ReadStruct(v))
+	assert.Error(t, bb.ReadStruct((*testStruct)(nil)))
+	n := 0
+	assert.Error(t, bb.ReadStruct(&n))
+
+	// Reached EOF at every possible offset
+	for i := 0; i < len(testStructBytes); i++ {
+		bb = NewReaderBytes(testStructBytes[:i], binary.BigEndian)
+		assert.Error(t, bb.ReadStruct(&v), "truncated at %d", i)
+	}
+
+	// invalid definitions
+	invalid := []interface{}{
+		&struct{ A int }{},
+		&struct{ A []byte }{},
+		&struct{ A string }{},
+		&struct{ A map[int]int }{},
+		&struct {
+			A uint8 `bin:"bogus"`
+		}{},
+		&struct {
+			A uint8 `bin:"skip=-1"`
+		}{},
+		&struct {
+			A []byte `bin:"len="`
+		}{},
+		&struct {
+			A []byte `bin:"len=N"`
+		}{},
+		&struct {
+			A []byte `bin:"len=N"`
+			N uint8
+		}{},
+		&struct {
+			N float32
+			A []byte `bin:"len=N"`
+		}{},
+		&struct {
+			N uint8
+			A uint8 `bin:"len=N"`
+		}{},
+		&struct {
+			_ []byte
+		}{},
+	}
+	for _, ptr := range invalid {
+		bb = NewReaderBytes(make([]byte, 64), binary.BigEndian)
+		assert.Error(t, bb.ReadStruct(ptr), "%T", ptr)
+	}
+
+	// negative length
+	bb = NewReaderBytes([]byte{0xFF}, binary.BigEndian)
+	assert.Error(t, bb.ReadStruct(&struct {
+		N int8
+		A []byte `bin:"len=N"`
+	}{}))
+}
+
+func TestReadStructHostileLength(t *testing.T) {
+	// lengths which cannot be allocated are rejected
+	bb := NewReaderBytes([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, binary.BigEndian)
+	assert.True(t, errors.Is(bb.ReadStruct(&struct {
+		N uint64
+		A []byte `bin:"len=N"`
+	}{}), ErrTooLong))
+
+	// and those which can are only allocated as the bytes arrive, so that a
+	// truncated stream fails rather than allocating for the whole length
+	src := []byte{0x7F, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 'a', 'b', 'c'}
+	bb = NewReaderBytes(src, binary.BigEndian)
+	var e *Error
+	err := bb.ReadStruct(&struct {
+		N uint64
+		A []byte `bin:"len=N"`
+	}{})
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, 3, e.Got)
+	bb = NewReaderBytes(src, binary.BigEndian)
+	assert.True(t, errors.Is(bb.ReadStruct(&struct {
+		N int64
+		A string `bin:"len=N"`
+	}{}), io.ErrUnexpectedEOF))
+	bb = NewReaderBytes(src, binary.BigEndian)
+	assert.True(t, errors.Is(bb.ReadStruct(&struct {
+		N uint64
+		A []uint32 `bin:"len=N"`
+	}{}), io.ErrUnexpectedEOF))
+
+	// a short stream with a length of gigabytes costs no more than a bounded
+	// allocation
+	src[0], src[1], src[2], src[3] = 0, 0, 0, 0
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	bb = NewReaderBytes(src, binary.BigEndian)
+	assert.Error(t, bb.ReadStruct(&struct {
+		N uint64
+		A []uint16 `bin:"len=N"`
+	}{}))
+	bb = NewReaderBytes(src, binary.BigEndian)
+	assert.Error(t, bb.ReadStruct(&struct {
+		N uint64
+		A string `bin:"len=N"`
+	}{}))
+	runtime.ReadMemStats(&after)
+	assert.True(t, after.TotalAlloc-before.TotalAlloc < 4*allocChunk, "allocated %d bytes", after.TotalAlloc-before.TotalAlloc)
+
+	// lengths spanning several steps of growth are read in full
+	long := make([]byte, 4+3*allocChunk)
+	binary.BigEndian.PutUint32(long, 3*allocChunk/2)
+	for i := range long[4:] {
+		long[4+i] = byte(i)
+	}
+	bb = NewReaderBytes(long, binary.BigEndian)
+	v := struct {
+		N uint32
+		A []uint16 `bin:"len=N"`
+	}{}
+	assert.NoError(t, bb.ReadStruct(&v))
+	assert.Equal(t, 3*allocChunk/2, len(v.A))
+	assert.Equal(t, uint16(0x0001), v.A[0])
+	assert.Equal(t, uint16(0xFEFF), v.A[len(v.A)-1])
+	bb = NewReaderBytes(long, binary.BigEndian)
+	w := struct {
+		N uint32
+		A []byte `bin:"len=N"`
+	}{}
+	assert.NoError(t, bb.ReadStruct(&w))
+	assert.Equal(t, long[4:4+3*allocChunk/2], w.A)
+}
+
+func TestWriteStruct(t *testing.T) {
+	t.Parallel()
+	expected := append([]byte{}, testStructBytes...)
+	expected[7] = 0x00 // padding is written as null-bytes
+
+	// by pointer
+	w := bytes.NewBuffer([]byte{})
+	bw := NewWriter(w, binary.BigEndian)
+	v := testStructValue
+	assert.NoError(t, bw.WriteStruct(&v))
+	assert.Equal(t, expected, w.Bytes())
+	assert.Equal(t, int64(len(expected)), bw.GetPosition())
+	// per-field byte orders do not leak
+	assert.Equal(t, binary.BigEndian, bw.GetByteOrder())
+
+	// by value
+	w = bytes.NewBuffer([]byte{})
+	bw = NewWriter(w, binary.BigEndian)
+	assert.NoError(t, bw.WriteStruct(testStructValue))
+	assert.Equal(t, expected, w.Bytes())
+}
+
+func TestWriteStructLengths(t *testing.T) {
+	t.Parallel()
+	expected := append([]byte{}, testStructBytes...)
+	expected[7] = 0x00
+
+	// zero-valued length fields are filled in automatically
+	v := testStructValue
+	v.Count = 0
+	v.NameLen = 0
+	v.DataLen = 0
+	w := bytes.NewBuffer([]byte{})
+	bw := NewWriter(w, binary.BigEndian)
+	assert.NoError(t, bw.WriteStruct(v))
+	assert.Equal(t, expected, w.Bytes())
+	assert.Equal(t, uint8(0), v.Count)
+
+	// several fields may share a length field
+	shared := struct {
+		N uint16
+		A []uint8  `bin:"len=N"`
+		B []uint16 `bin:"len=N"`
+	}{A: []uint8{1, 2}, B: []uint16{3, 4}}
+	w = bytes.NewBuffer([]byte{})
+	bw = NewWriter(w, binary.LittleEndian)
+	assert.NoError(t, bw.WriteStruct(shared))
+	assert.Equal(t, []byte{0x02, 0x00, 0x01, 0x02, 0x03, 0x00, 0x04, 0x00}, w.Bytes())
+}
+
+func TestWriteStructRoundTrip(t *testing.T) {
+	t.Parallel()
+	for _, bo := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		w := bytes.NewBuffer([]byte{})
+		bw := NewWriter(w, bo)
+		assert.NoError(t, bw.WriteStruct(testStructValue))
+
+		br := NewReaderBytes(w.Bytes(), bo)
+		v := testStruct{}
+		assert.NoError(t, br.ReadStruct(&v))
+		assert.Equal(t, testStructValue, v)
+		assert.Equal(t, bw.GetPosition(), br.GetPosition())
+
+		// and back again, byte-for-byte
+		w2 := bytes.NewBuffer([]byte{})
+		bw = NewWriter(w2, bo)
+		assert.NoError(t, bw.WriteStruct(&v))
+		assert.Equal(t, w.Bytes(), w2.Bytes())
+	}
+}
+
+func TestWriteStructError(t *testing.T) {
+	t.Parallel()
+	// nil writer
+	bw := Writer{}
+	bw.bo = binary.LittleEndian
+	assert.Error(t, bw.WriteStruct(testStructValue))
+
+	// nil byte order
+	bw = Writer{}
+	bw.dest = bytes.NewBuffer([]byte{})
+	assert.Error(t, bw.WriteStruct(testStructValue))
+
+	// not a struct
+	bw = NewWriter(bytes.NewBuffer([]byte{}), binary.LittleEndian)
+	assert.Error(t, bw.WriteStruct(1))
+	assert.Error(t, bw.WriteStruct((*testStruct)(nil)))
+
+	// writer error
+	bw = NewWriter(errRW, binary.LittleEndian)
+	assert.Error(t, bw.WriteStruct(testStructValue))
+
+	// length field mismatches
+	v := testStructValue
+	v.Count = 3
+	bw = NewWriter(bytes.NewBuffer([]byte{}), binary.LittleEndian)
+	assert.Error(t, bw.WriteStruct(v))
+	v = testStructValue
+	v.NameLen = 1
+	assert.Error(t, bw.WriteStruct(v))
+
+	invalid := []interface{}{
+		struct{ A int }{},
+		struct{ A []byte }{},
+		struct{ A string }{},
+		struct{ A [2][]byte }{},
+		struct {
+			A uint8 `bin:"bogus"`
+		}{},
+		struct {
+			A []byte `bin:"len=N"`
+		}{},
+		struct {
+			N uint8
+			A uint8 `bin:"len=N"`
+		}{},
+		struct {
+			_ []byte
+		}{},
+		// length overflows length field
+		struct {
+			N uint8
+			A []byte `bin:"len=N"`
+		}{A: make([]byte, 256)},
+		struct {
+			N int8
+			A string `bin:"len=N"`
+		}{A: string(make([]byte, 128))},
+		// conflicting lengths
+		struct {
+			N uint8
+			A []byte `bin:"len=N"`
+			B []byte `bin:"len=N"`
+		}{A: make([]byte, 1), B: make([]byte, 2)},
+		// unsized length field
+		struct {
+			N int
+			A []byte `bin:"len=N"`
+		}{},
+	}
+	for _, s := range invalid {
+		bw = NewWriter(bytes.NewBuffer([]byte{}), binary.LittleEndian)
+		assert.Error(t, bw.WriteStruct(s), "%T", s)
+	}
+}
+
+/*
+===============================================================================
+    ReaderAt
+===============================================================================
+*/
+
+func TestReaderAt(t *testing.T) {
+	t.Parallel()
+	buf := []byte{0x08, 0x00, 0xFF, 0x01, 0x08, 0x00, 0xFF, 0x01, 0x77, 0xBE, 0x9F, 0x1A, 0x2F, 0xDD, 0x5E, 0x40}
+	ra := NewReaderAtBytes(buf, binary.LittleEndian)
+	c := byte(0)
+	ui16 := uint16(0)
+	ui32 := uint32(0)
+	ui64 := uint64(0)
+	i16 := int16(0)
+	i32 := int32(0)
+	i64 := int64(0)
+	f32 := float32(0)
+	f64 := float64(0)
+
+	// offsets may be visited in any order
+	assert.NoError(t, ra.ReadFloat64At(&f64, 8))
+	assert.Equal(t, float64(123.456), f64)
+	assert.NoError(t, ra.ReadUint16At(&ui16, 2))
+	assert.Equal(t, uint16(0x01FF), ui16)
+	assert.NoError(t, ra.ReadUint32At(&ui32, 0))
+	assert.Equal(t, uint32(0x01FF0008), ui32)
+	assert.NoError(t, ra.ReadUint64At(&ui64, 0))
+	assert.Equal(t, uint64(0x01FF000801FF0008), ui64)
+	assert.NoError(t, ra.ReadByteAt(&c, 3))
+	assert.Equal(t, byte(0x01), c)
+	assert.NoError(t, ra.ReadInt16At(&i16, 1))
+	assert.Equal(t, int16(-256), i16)
+	assert.NoError(t, ra.ReadInt32At(&i32, 12))
+	assert.Equal(t, int32(0x405EDD2F), i32)
+	assert.NoError(t, ra.ReadInt64At(&i64, 8))
+	assert.Equal(t, int64(0x405EDD2F1A9FBE77), i64)
+
+	tmp := make([]byte, 4)
+	assert.NoError(t, ra.ReadBytesAt(tmp, 4))
+	assert.Equal(t, buf[4:8], tmp)
+
+	// Big Endian
+	ra.SetByteOrder(binary.BigEndian)
+	assert.Equal(t, binary.BigEndian, ra.GetByteOrder())
+	assert.NoError(t, ra.ReadUint16At(&ui16, 0))
+	assert.Equal(t, uint16(0x0800), ui16)
+	ra = NewReaderAtBytes([]byte{0x42, 0xf6, 0xe9, 0x79}, binary.BigEndian)
+	assert.NoError(t, ra.ReadFloat32At(&f32, 0))
+	assert.Equal(t, float32(123.456), f32)
+}
+
+func TestReaderAtConcurrent(t *testing.T) {
+	t.Parallel()
+	buf := make([]byte, 4096)
+	for i := 0; i < len(buf)/4; i++ {
+		binary.BigEndian.PutUint32(buf[i*4:], uint32(i))
+	}
+	ra := NewReaderAtBytes(buf, binary.BigEndian)
+	done := make(chan bool)
+	for g := 0; g < 8; g++ {
+		go func(g int) {
+			ui32 := uint32(0)
+			for i := g; i < len(buf)/4; i += 8 {
+				if err := ra.ReadUint32At(&ui32, int64(i*4)); err != nil || ui32 != uint32(i) {
+					done <- false
+					return
+				}
+			}
+			done <- true
+		}(g)
+	}
+	for g := 0; g < 8; g++ {
+		assert.True(t, <-done)
+	}
+}
+
+func TestReaderAtError(t *testing.T) {
+	t.Parallel()
+	c := byte(0)
+	ui16 := uint16(0)
+	i16 := int16(0)
+	i32 := int32(0)
+	i64 := int64(0)
+	f32 := float32(0)
+	f64 := float64(0)
+
+	// nil reader
+	ra := ReaderAt{}
+	ra.bo = binary.LittleEndian
+	assert.Error(t, ra.ReadBytesAt(make([]byte, 2), 0))
+	assert.Error(t, ra.ReadByteAt(&c, 0))
+	assert.Error(t, ra.ReadUint16At(&ui16, 0))
+
+	// nil byte order
+	ra = ReaderAt{}
+	ra.source = bytes.NewReader(testBuffer)
+	assert.Error(t, ra.ReadUint16At(&ui16, 0))
+	assert.Error(t, ra.ReadInt16At(&i16, 0))
+	assert.Error(t, ra.ReadInt32At(&i32, 0))
+	assert.Error(t, ra.ReadInt64At(&i64, 0))
+	assert.Error(t, ra.ReadFloat32At(&f32, 0))
+	assert.Error(t, ra.ReadFloat64At(&f64, 0))
+
+	// beyond EOF
+	ra = NewReaderAtBytes(testBuffer, binary.LittleEndian)
+	assert.True(t, errors.Is(ra.ReadByteAt(&c, int64(len(testBuffer))), io.EOF))
+	// partially beyond EOF
+	assert.True(t, errors.Is(ra.ReadUint16At(&ui16, int64(len(testBuffer)-1)), io.ErrUnexpectedEOF))
+	// negative offset
+	assert.Error(t, ra.ReadUint16At(&ui16, -1))
+
+	// source error
+	ra = NewReaderAt(errRW, binary.LittleEndian)
+	assert.Error(t, ra.ReadUint16At(&ui16, 0))
+}
+
+func TestReaderAtAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops buffers at random under the race detector")
+	}
+	ra := NewReaderAtBytes(make([]byte, 16), binary.BigEndian)
+	c := byte(0)
+	ui32 := uint32(0)
+	i64 := int64(0)
+	f64 := float64(0)
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = ra.ReadByteAt(&c, 1)
+		_ = ra.ReadUint32At(&ui32, 2)
+		_ = ra.ReadInt64At(&i64, 3)
+		_ = ra.ReadFloat64At(&f64, 8)
+	})
+	assert.Equal(t, 0.0, allocs)
+}
+
+/*
+===============================================================================
+    BitReader
+===============================================================================
+*/
+
+func TestBitReaderMSBFirst(t *testing.T) {
+	t.Parallel()
+	r := NewReaderBytes([]byte{0xB2, 0x7F, 0xC0}, binary.LittleEndian)
+	br := NewBitReader(&r, MSBFirst)
+	v := uint64(0)
+	bit := true
+
+	assert.NoError(t, br.ReadBits(&v, 3))
+	assert.Equal(t, uint64(0x5), v) // 101
+	assert.NoError(t, br.ReadBits(&v, 5))
+	assert.Equal(t, uint64(0x12), v) // 10010
+	assert.Equal(t, int64(8), br.GetBitPosition())
+	assert.NoError(t, br.ReadBool(&bit))
+	assert.False(t, bit)
+	// crosses a byte boundary
+	assert.NoError(t, br.ReadBits(&v, 9))
+	assert.Equal(t, uint64(0x1FF), v) // 1111111 11
+	assert.Equal(t, int64(18), br.GetBitPosition())
+	assert.Equal(t, int64(3), r.GetPosition())
+}
+
+func TestBitReaderLSBFirst(t *testing.T) {
+	t.Parallel()
+	r := NewReaderBytes([]byte{0xB2, 0x7F, 0x03}, binary.LittleEndian)
+	br := NewBitReader(&r, LSBFirst)
+	v := uint64(0)
+	bit := false
+
+	assert.NoError(t, br.ReadBits(&v, 3))
+	assert.Equal(t, uint64(0x2), v) // 010
+	assert.NoError(t, br.ReadBits(&v, 5))
+	assert.Equal(t, uint64(0x16), v) // 10110
+	assert.NoError(t, br.ReadBool(&bit))
+	assert.True(t, bit)
+	// crosses a byte boundary
+	assert.NoError(t, br.ReadBits(&v, 9))
+	assert.Equal(t, uint64(0x1BF), v) // 1 0111111
+	assert.Equal(t, int64(18), br.GetBitPosition())
+	assert.Equal(t, LSBFirst, br.GetBitOrder())
+}
+
+func TestBitReaderWide(t *testing.T) {
+	t.Parallel()
+	buf := []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xAB, 0xCD, 0xEF, 0xFF}
+	v := uint64(0)
+
+	r := NewReaderBytes(buf, binary.LittleEndian)
+	br := NewBitReader(&r, MSBFirst)
+	assert.NoError(t, br.ReadBits(&v, 64))
+	assert.Equal(t, binary.BigEndian.Uint64(buf), v)
+
+	r = NewReaderBytes(buf, binary.LittleEndian)
+	br = NewBitReader(&r, LSBFirst)
+	assert.NoError(t, br.ReadBits(&v, 64))
+	assert.Equal(t, binary.LittleEndian.Uint64(buf), v)
+
+	// unaligned 60-bit field
+	r = NewReaderBytes(buf, binary.LittleEndian)
+	br = NewBitReader(&r, MSBFirst)
+	assert.NoError(t, br.ReadBits(&v, 4))
+	assert.NoError(t, br.ReadBits(&v, 60))
+	assert.Equal(t, binary.BigEndian.Uint64(buf)&0x0FFFFFFFFFFFFFFF, v)
+	assert.Equal(t, int64(64), br.GetBitPosition())
+}
+
+func TestBitReaderPeekBits(t *testing.T) {
+	t.Parallel()
+	r := NewReaderBytes([]byte{0xB2, 0x7F}, binary.LittleEndian)
+	br := NewBitReader(&r, MSBFirst)
+	v := uint64(0)
+
+	assert.NoError(t, br.PeekBits(&v, 4))
+	assert.Equal(t, uint64(0xB), v)
+	assert.Equal(t, int64(0), br.GetBitPosition())
+	assert.NoError(t, br.PeekBits(&v, 12))
+	assert.Equal(t, uint64(0xB27), v)
+	assert.Equal(t, int64(0), br.GetBitPosition())
+	assert.NoError(t, br.ReadBits(&v, 4))
+	assert.Equal(t, uint64(0xB), v)
+	assert.Equal(t, int64(4), br.GetBitPosition())
+}
+
+func TestBitReaderAlignToByte(t *testing.T) {
+	t.Parallel()
+	r := NewReaderBytes([]byte{0xFF, 0x12, 0x34}, binary.BigEndian)
+	br := NewBitReader(&r, MSBFirst)
+	v := uint64(0)
+
+	// aligning when already aligned is a no-op
+	br.AlignToByte()
+	assert.Equal(t, int64(0), br.GetBitPosition())
+
+	assert.NoError(t, br.ReadBits(&v, 3))
+	br.AlignToByte()
+	assert.Equal(t, int64(8), br.GetBitPosition())
+
+	// the underlying reader can be used directly once aligned
+	ui16 := uint16(0)
+	assert.NoError(t, r.ReadUint16(&ui16))
+	assert.Equal(t, uint16(0x1234), ui16)
+}
+
+func TestBitReaderError(t *testing.T) {
+	t.Parallel()
+	v := uint64(0)
+	bit := false
+
+	// nil reader
+	br := BitReader{}
+	assert.Error(t, br.ReadBits(&v, 1))
+	assert.Error(t, br.PeekBits(&v, 1))
+	assert.Error(t, br.ReadBool(&bit))
+	assert.Equal(t, int64(0), br.GetBitPosition())
+
+	// invalid widths
+	r := NewReaderBytes(testBuffer, binary.LittleEndian)
+	br = NewBitReader(&r, MSBFirst)
+	assert.Error(t, br.ReadBits(&v, 65))
+	assert.True(t, errors.Is(br.PeekBits(&v, 57), ErrTooLong))
+
+	// Reached EOF
+	r = NewReaderBytes([]byte{0xFF}, binary.LittleEndian)
+	br = NewBitReader(&r, LSBFirst)
+	assert.Error(t, br.ReadBits(&v, 9))
+	r = NewReaderBytes([]byte{}, binary.LittleEndian)
+	br = NewBitReader(&r, LSBFirst)
+	assert.Error(t, br.ReadBool(&bit))
+	assert.Error(t, br.PeekBits(&v, 1))
+	r = NewReaderBytes([]byte{0xFF, 0xFF, 0xFF, 0xFF}, binary.LittleEndian)
+	br = NewBitReader(&r, MSBFirst)
+	assert.Error(t, br.ReadBits(&v, 64))
+}
+
+/*
+===============================================================================
+    BitWriter
+===============================================================================
+*/
+
+func TestBitWriterMSBFirst(t *testing.T) {
+	t.Parallel()
+	out := bytes.NewBuffer([]byte{})
+	w := NewWriter(out, binary.LittleEndian)
+	bw := NewBitWriter(&w, MSBFirst)
+
+	assert.NoError(t, bw.WriteBits(0x5, 3))
+	assert.NoError(t, bw.WriteBits(0x12, 5))
+	assert.Equal(t, []byte{0xB2}, out.Bytes())
+	assert.Equal(t, int64(1), w.GetPosition())
+	assert.NoError(t, bw.WriteBool(false))
+	assert.NoError(t, bw.WriteBits(0x1FF, 9))
+	assert.Equal(t, int64(18), bw.GetBitPosition())
+	assert.Equal(t, int64(2), w.GetPosition())
+	assert.NoError(t, bw.Flush())
+	assert.Equal(t, []byte{0xB2, 0x7F, 0xC0}, out.Bytes())
+	assert.Equal(t, int64(3), w.GetPosition())
+	assert.Equal(t, int64(24), bw.GetBitPosition())
+}
+
+func TestBitWriterLSBFirst(t *testing.T) {
+	t.Parallel()
+	out := bytes.NewBuffer([]byte{})
+	w := NewWriter(out, binary.LittleEndian)
+	bw := NewBitWriter(&w, LSBFirst)
+
+	assert.NoError(t, bw.WriteBits(0x2, 3))
+	assert.NoError(t, bw.WriteBits(0x16, 5))
+	assert.NoError(t, bw.WriteBool(true))
+	assert.NoError(t, bw.WriteBits(0x1BF, 9))
+	assert.NoError(t, bw.Flush())
+	assert.Equal(t, []byte{0xB2, 0x7F, 0x03}, out.Bytes())
+	assert.Equal(t, LSBFirst, bw.GetBitOrder())
+}
+
+func TestBitWriterWide(t *testing.T) {
+	t.Parallel()
+	v := uint64(0x0123456789ABCDEF)
+
+	out := bytes.NewBuffer([]byte{})
+	w := NewWriter(out, binary.LittleEndian)
+	bw := NewBitWriter(&w, MSBFirst)
+	assert.NoError(t, bw.WriteBits(v, 64))
+	assert.Equal(t, v, binary.BigEndian.Uint64(out.Bytes()))
+
+	out = bytes.NewBuffer([]byte{})
+	w = NewWriter(out, binary.LittleEndian)
+	bw = NewBitWriter(&w, LSBFirst)
+	assert.NoError(t, bw.WriteBits(v, 64))
+	assert.Equal(t, v, binary.LittleEndian.Uint64(out.Bytes()))
+
+	// bits above `n` are ignored
+	out = bytes.NewBuffer([]byte{})
+	w = NewWriter(out, binary.LittleEndian)
+	bw = NewBitWriter(&w, MSBFirst)
+	assert.NoError(t, bw.WriteBits(0xFFFF, 4))
+	assert.NoError(t, bw.Flush())
+	assert.Equal(t, []byte{0xF0}, out.Bytes())
+}
+
+func TestBitWriterPadding(t *testing.T) {
+	t.Parallel()
+	out := bytes.NewBuffer([]byte{})
+	w := NewWriter(out, binary.LittleEndian)
+	bw := NewBitWriter(&w, MSBFirst)
+	bw.SetPaddingBit(true)
+
+	// aligning when already aligned is a no-op
+	assert.NoError(t, bw.AlignToByte())
+	assert.Equal(t, int64(0), w.GetPosition())
+
+	assert.NoError(t, bw.WriteBits(0, 3))
+	assert.NoError(t, bw.AlignToByte())
+	assert.Equal(t, []byte{0x1F}, out.Bytes())
+
+	bw.SetBitOrder(LSBFirst)
+	assert.NoError(t, bw.WriteBits(0, 3))
+	assert.NoError(t, bw.AlignToByte())
+	assert.Equal(t, []byte{0x1F, 0xF8}, out.Bytes())
+}
+
+func TestBitWriterRoundTrip(t *testing.T) {
+	t.Parallel()
+	widths := []uint{1, 3, 7, 8, 13, 16, 31, 33, 56, 57, 63, 64, 2, 5}
+	for _, order := range []BitOrder{MSBFirst, LSBFirst} {
+		out := bytes.NewBuffer([]byte{})
+		w := NewWriter(out, binary.LittleEndian)
+		bw := NewBitWriter(&w, order)
+		for i, n := range widths {
+			assert.NoError(t, bw.WriteBits(uint64(0x9E3779B97F4A7C15)*uint64(i+1), n))
+		}
+		assert.NoError(t, bw.WriteBool(true))
+		bitLen := bw.GetBitPosition()
+		assert.NoError(t, bw.Flush())
+
+		r := NewReaderBytes(out.Bytes(), binary.LittleEndian)
+		br := NewBitReader(&r, order)
+		v := uint64(0)
+		for i, n := range widths {
+			assert.NoError(t, br.ReadBits(&v, n))
+			assert.Equal(t, (uint64(0x9E3779B97F4A7C15)*uint64(i+1))&bitMask(n), v)
+		}
+		bit := false
+		assert.NoError(t, br.ReadBool(&bit))
+		assert.True(t, bit)
+		assert.Equal(t, bitLen, br.GetBitPosition())
+	}
+}
+
+func TestBitWriterError(t *testing.T) {
+	t.Parallel()
+	// nil writer
+	bw := BitWriter{}
+	assert.Error(t, bw.WriteBits(1, 1))
+	assert.Error(t, bw.WriteBool(true))
+	assert.Error(t, bw.AlignToByte())
+	assert.Error(t, bw.Flush())
+	assert.Equal(t, int64(0), bw.GetBitPosition())
+
+	// invalid width
+	w := NewWriter(bytes.NewBuffer([]byte{}), binary.LittleEndian)
+	bw = NewBitWriter(&w, MSBFirst)
+	assert.Error(t, bw.WriteBits(1, 65))
+
+	// writer error
+	w = NewWriter(errRW, binary.LittleEndian)
+	bw = NewBitWriter(&w, MSBFirst)
+	assert.Error(t, bw.WriteBits(0xFF, 8))
+	assert.Error(t, bw.WriteBits(0xFFFFFFFFFFFFFFFF, 64))
+	bw = NewBitWriter(&w, LSBFirst)
+	assert.Error(t, bw.WriteBits(0xFFFFFFFFFFFFFFFF, 64))
+
+	// a failed write leaves the cached bits in place
+	bw = NewBitWriter(&w, MSBFirst)
+	assert.NoError(t, bw.WriteBits(5, 3))
+	assert.Error(t, bw.WriteBits(0xFF, 8))
+	assert.Error(t, bw.Flush())
+	assert.Equal(t, int64(3), bw.GetBitPosition())
+}
+
+/*
+===============================================================================
+    baseBinary
+===============================================================================
+*/
 
 func TestGetPosition(t *testing.T) {
 	t.Parallel()
-	buf := []byte("1234567890abcdef")
-	bb := NewReaderBytes(buf, binary.LittleEndian)
-	ui16 := uint16(0)
-	ui32 := uint32(0)
-	tmp3 := make([]byte, 3)
-	c := byte(0)
-	assert.Equal(t, int64(0), bb.GetPosition())
-	bb.Discard(4)
-	assert.Equal(t, int64(4), bb.GetPosition())
-	bb.ReadByte(&c)
-	assert.Equal(t, int64(5), bb.GetPosition())
-	bb.ReadUint16(&ui16)
-	assert.Equal(t, int64(7), bb.GetPosition())
-	bb.ReadUint32(&ui32)
-	assert.Equal(t, int64(11), bb.GetPosition())
-	bb.ReadBytes(tmp3)
-	assert.Equal(t, int64(14), bb.GetPosition())
+	buf := []byte("1234567890abcdef")
+	bb := NewReaderBytes(buf, binary.LittleEndian)
+	ui16 := uint16(0)
+	ui32 := uint32(0)
+	tmp3 := make([]byte, 3)
+	c := byte(0)
+	assert.Equal(t, int64(0), bb.GetPosition())
+	bb.Discard(4)
+	assert.Equal(t, int64(4), bb.GetPosition())
+	bb.ReadByte(&c)
+	assert.Equal(t, int64(5), bb.GetPosition())
+	bb.ReadUint16(&ui16)
+	assert.Equal(t, int64(7), bb.GetPosition())
+	bb.ReadUint32(&ui32)
+	assert.Equal(t, int64(11), bb.GetPosition())
+	bb.ReadBytes(tmp3)
+	assert.Equal(t, int64(14), bb.GetPosition())
+}
+
+func TestGetByteOrder(t *testing.T) {
+	t.Parallel()
+	bb := NewReaderBytes([]byte{}, binary.LittleEndian)
+	assert.Equal(t, binary.LittleEndian, bb.GetByteOrder())
+
+	bb = NewReaderBytes([]byte{}, binary.BigEndian)
+	assert.Equal(t, binary.BigEndian, bb.GetByteOrder())
+}
+
+func TestSetByteOrder(t *testing.T) {
+	t.Parallel()
+	bb := NewReaderBytes([]byte{}, binary.LittleEndian)
+	bb.SetByteOrder(binary.BigEndian)
+	assert.Equal(t, binary.BigEndian, bb.GetByteOrder())
+	bb.SetByteOrder(binary.LittleEndian)
+	assert.Equal(t, binary.LittleEndian, bb.GetByteOrder())
+}
+
+/*
+===============================================================================
+    Errors
+===============================================================================
+*/
+
+func TestError(t *testing.T) {
+	t.Parallel()
+	e := &Error{Op: "ReadUint32", Offset: 12, Requested: 4, Got: 1, Err: io.ErrUnexpectedEOF}
+	assert.Equal(t, "bin: ReadUint32 at offset 12 (1 of 4 bytes): unexpected EOF", e.Error())
+	assert.Equal(t, io.ErrUnexpectedEOF, e.Unwrap())
+
+	// the prefix of sentinel errors is not repeated
+	e = &Error{Op: "ReadUint16", Offset: 3, Err: ErrNilByteOrder}
+	assert.Equal(t, "bin: ReadUint16 at offset 3: byte order is not set", e.Error())
+
+	e = &Error{Op: "Discard"}
+	assert.Equal(t, "bin: Discard at offset 0", e.Error())
+}
+
+func TestErrorIO(t *testing.T) {
+	t.Parallel()
+	var (
+		e    *Error
+		ui16 uint16
+		ui32 uint32
+		ui64 uint64
+		i32  int32
+		i64  int64
+	)
+	// partial read
+	bb := NewReaderBytes([]byte{0x01, 0x02, 0x03, 0x04, 0x05}, binary.LittleEndian)
+	assert.NoError(t, bb.ReadUint16(&ui16))
+	err := bb.ReadUint64(&ui64)
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, Error{Op: "ReadUint64", Offset: 2, Requested: 8, Got: 3, Err: io.ErrUnexpectedEOF}, *e)
+
+	// nothing left to read
+	err = bb.ReadByte(&c)
+	assert.True(t, errors.Is(err, io.EOF))
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, Error{Op: "ReadByte", Offset: 5, Requested: 1, Got: 0, Err: io.EOF}, *e)
+
+	// discards are described as a whole
+	bb = NewReaderBytes(make([]byte, 2000), binary.LittleEndian)
+	err = bb.Discard(3000)
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, Error{Op: "Discard", Offset: 0, Requested: 3000, Got: 2000, Err: io.ErrUnexpectedEOF}, *e)
+
+	// varints report the offset at which they begin
+	bb = NewReaderBytes([]byte{0x00, 0x80, 0x80}, binary.LittleEndian)
+	assert.NoError(t, bb.ReadUvarint(&ui64))
+	err = bb.ReadVarint(&i64)
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, "ReadVarint", e.Op)
+	assert.Equal(t, int64(1), e.Offset)
+
+	// causes from the source are preserved
+	bb = NewReader(errRW, binary.LittleEndian)
+	err = bb.ReadUint32(&ui32)
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, "error", e.Err.Error())
+
+	// writes
+	w := NewWriter(errRW, binary.BigEndian)
+	err = w.WriteFloat64(1.5)
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, Error{Op: "WriteFloat64", Offset: 0, Requested: 8, Got: 0, Err: e.Err}, *e)
+	err = w.ZeroFill(2000)
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, "ZeroFill", e.Op)
+	assert.Equal(t, 2000, e.Requested)
+
+	// short writes
+	w = NewWriter(negRW, binary.BigEndian)
+	assert.True(t, errors.Is(w.WriteUint16(1), io.ErrShortWrite))
+
+	// reads at an offset
+	ra := NewReaderAtBytes([]byte{0x01, 0x02, 0x03}, binary.LittleEndian)
+	err = ra.ReadInt32At(&i32, 1)
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, Error{Op: "ReadInt32At", Offset: 1, Requested: 4, Got: 2, Err: io.ErrUnexpectedEOF}, *e)
+
+	// bits
+	br := NewBitReader(&bb, MSBFirst)
+	bb = NewReaderBytes([]byte{0xFF}, binary.LittleEndian)
+	assert.NoError(t, br.ReadBits(&ui64, 4))
+	err = br.ReadBits(&ui64, 8)
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, Error{Op: "ReadBits", Offset: 1, Requested: 1, Got: 0, Err: io.EOF}, *e)
+}
+
+func TestErrorSentinels(t *testing.T) {
+	t.Parallel()
+	var (
+		e    *Error
+		ui16 uint16
+		ui32 uint32
+		ui64 uint64
+		i8   int8
+		i16  int16
+		i32  int32
+		i64  int64
+		f32  float32
+		f64  float64
+	)
+	r := Reader{}
+	w := Writer{}
+	bw := BitWriter{}
+	br := BitReader{}
+	_, seekErr := r.Seek(0, io.SeekStart)
+	_, subErr := r.SubReader(1)
+	ra := ReaderAt{}
+	for i, err := range []error{
+		r.ReadByte(&c), r.ReadBytes(buf), r.ReadUint16(&ui16), r.ReadUint32(&ui32), r.ReadUint64(&ui64),
+		r.ReadInt8(&i8), r.ReadInt16(&i16), r.ReadInt32(&i32), r.ReadInt64(&i64),
+		r.ReadFloat32(&f32), r.ReadFloat64(&f64), r.ReadUvarint(&ui64), r.ReadVarint(&i64),
+		r.Discard(1), r.Peek(make([]byte, 1)), seekErr, r.SetPosition(0), subErr, r.ReadStruct(&testStruct{}),
+		w.WriteByte(0), w.WriteBytes([]byte{0}), w.WriteUint16(0), w.WriteUint32(0), w.WriteUint64(0),
+		w.WriteInt8(0), w.WriteInt16(0), w.WriteInt32(0), w.WriteInt64(0),
+		w.WriteFloat32(0), w.WriteFloat64(0), w.WriteUvarint(0), w.WriteVarint(0),
+		w.ZeroFill(1), w.WriteStruct(testStruct{}),
+		ra.ReadBytesAt(buf, 0), ra.ReadByteAt(&c, 0), ra.ReadUint16At(&ui16, 0), ra.ReadFloat64At(&f64, 0),
+		br.ReadBits(&ui64, 1), br.ReadBool(new(bool)), br.PeekBits(&ui64, 1),
+		bw.WriteBits(0, 1), bw.WriteBool(true), bw.AlignToByte(),
+	} {
+		assert.True(t, errors.Is(err, ErrNilSource), "%d: %v", i, err)
+		assert.True(t, errors.As(err, &e), "%d: %v", i, err)
+	}
+
+	r = NewReaderBytes(make([]byte, 8), nil)
+	w = NewWriter(blackHole, nil)
+	ra = NewReaderAtBytes(make([]byte, 8), nil)
+	for i, err := range []error{
+		r.ReadUint16(&ui16), r.ReadUint32(&ui32), r.ReadUint64(&ui64),
+		r.ReadInt16(&i16), r.ReadInt32(&i32), r.ReadInt64(&i64),
+		r.ReadFloat32(&f32), r.ReadFloat64(&f64), r.ReadStruct(&testStruct{}),
+		w.WriteUint16(0), w.WriteUint32(0), w.WriteUint64(0),
+		w.WriteInt16(0), w.WriteInt32(0), w.WriteInt64(0),
+		w.WriteFloat32(0), w.WriteFloat64(0), w.WriteStruct(testStruct{}),
+		ra.ReadUint16At(&ui16, 0), ra.ReadInt64At(&i64, 0), ra.ReadFloat32At(&f32, 0),
+	} {
+		assert.True(t, errors.Is(err, ErrNilByteOrder), "%d: %v", i, err)
+		assert.True(t, errors.As(err, &e), "%d: %v", i, err)
+	}
+
+	r = NewReaderBytes([]byte{0xFF, 0x00}, binary.LittleEndian)
+	w = NewWriter(blackHole, binary.LittleEndian)
+	_, subErr = r.SubReader(-1)
+	type negative struct {
+		N    int8
+		Data []byte `bin:"len=N"`
+	}
+	for i, err := range []error{
+		r.Discard(-1), subErr, w.ZeroFill(-1), r.ReadStruct(&negative{}),
+	} {
+		assert.True(t, errors.Is(err, ErrNegativeLength), "%d: %v", i, err)
+		assert.True(t, errors.As(err, &e), "%d: %v", i, err)
+	}
+}
+
+/*
+===============================================================================
+    Length-Prefixed
+===============================================================================
+*/
+
+func TestPrefixed(t *testing.T) {
+	t.Parallel()
+	long := strings.Repeat("0123456789", 200)
+	for _, bo := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		for _, width := range []PrefixWidth{PrefixUint8, PrefixUint16, PrefixUint32, PrefixUvarint} {
+			out := bytes.NewBuffer([]byte{})
+			w := NewWriter(out, bo)
+			assert.NoError(t, w.WritePrefixedBytes([]byte{0xDE, 0xAD}, width))
+			assert.NoError(t, w.WritePrefixedString("hello", width))
+			assert.NoError(t, w.WritePrefixedBytes(nil, width))
+			if width != PrefixUint8 {
+				assert.NoError(t, w.WritePrefixedString(long, width))
+			}
+
+			r := NewReaderBytes(out.Bytes(), bo)
+			dst := []byte{}
+			assert.NoError(t, r.ReadPrefixedBytes(&dst, width, 16))
+			assert.Equal(t, []byte{0xDE, 0xAD}, dst)
+			s := ""
+			assert.NoError(t, r.ReadPrefixedString(&s, width, 16))
+			assert.Equal(t, "hello", s)
+			assert.NoError(t, r.ReadPrefixedBytes(&dst, width, 16))
+			assert.Equal(t, []byte{}, dst)
+			if width != PrefixUint8 {
+				assert.NoError(t, r.ReadPrefixedString(&s, width, len(long)))
+				assert.Equal(t, long, s)
+			}
+			assert.Equal(t, w.GetPosition(), r.GetPosition())
+		}
+	}
+	// exact encodings
+	out := bytes.NewBuffer([]byte{})
+	w := NewWriter(out, binary.BigEndian)
+	assert.NoError(t, w.WritePrefixedString("ab", PrefixUint8))
+	assert.NoError(t, w.WritePrefixedString("ab", PrefixUint16))
+	assert.NoError(t, w.WritePrefixedString("ab", PrefixUint32))
+	assert.NoError(t, w.WritePrefixedString(long, PrefixUvarint))
+	assert.Equal(t, []byte{
+		0x02, 'a', 'b',
+		0x00, 0x02, 'a', 'b',
+		0x00, 0x00, 0x00, 0x02, 'a', 'b',
+		0xD0, 0x0F,
+	}, out.Bytes()[:15])
+}
+
+func TestReadPrefixedBytesReusesSlice(t *testing.T) {
+	t.Parallel()
+	r := NewReaderBytes([]byte{0x03, 0x01, 0x02, 0x03}, binary.LittleEndian)
+	backing := make([]byte, 1, 8)
+	dst := backing
+	assert.NoError(t, r.ReadPrefixedBytes(&dst, PrefixUint8, 8))
+	assert.Equal(t, []byte{0x01, 0x02, 0x03}, dst)
+	assert.True(t, &backing[0] == &dst[0])
+}
+
+func TestReadPrefixedError(t *testing.T) {
+	t.Parallel()
+	var e *Error
+	dst := []byte{0xAA}
+	s := "unchanged"
+
+	// a hostile length is rejected before allocating
+	r := NewReaderBytes([]byte{0xFF, 0xFF, 0xFF, 0xFF}, binary.LittleEndian)
+	err := r.ReadPrefixedBytes(&dst, PrefixUint32, 1024)
+	assert.True(t, errors.Is(err, ErrTooLong))
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, Error{Op: "ReadPrefixedBytes", Offset: 0, Requested: math.MaxUint32, Err: ErrTooLong}, *e)
+	assert.Equal(t, []byte{0xAA}, dst)
+	r = NewReaderBytes([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01}, binary.LittleEndian)
+	assert.True(t, errors.Is(r.ReadPrefixedString(&s, PrefixUvarint, 1<<20), ErrTooLong))
+	assert.Equal(t, "unchanged", s)
+	r = NewReaderBytes([]byte{0x01, 'a'}, binary.LittleEndian)
+	assert.True(t, errors.Is(r.ReadPrefixedString(&s, PrefixUint8, 0), ErrTooLong))
+
+	// truncated
+	r = NewReaderBytes([]byte{0x03, 0x01, 0x02}, binary.LittleEndian)
+	err = r.ReadPrefixedBytes(&dst, PrefixUint8, 8)
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	assert.Equal(t, []byte{0xAA}, dst)
+	r = NewReaderBytes([]byte{0x00}, binary.LittleEndian)
+	assert.True(t, errors.Is(r.ReadPrefixedString(&s, PrefixUint16, 8), io.ErrUnexpectedEOF))
+	r = NewReaderBytes([]byte{}, binary.LittleEndian)
+	assert.True(t, errors.Is(r.ReadPrefixedString(&s, PrefixUvarint, 8), io.EOF))
+	assert.Equal(t, "unchanged", s)
+
+	// invalid arguments
+	r = NewReaderBytes([]byte{0x00, 0x00, 0x00, 0x00}, nil)
+	assert.True(t, errors.Is(r.ReadPrefixedBytes(&dst, PrefixUint16, 8), ErrNilByteOrder))
+	assert.True(t, errors.Is(r.ReadPrefixedString(&s, PrefixUint32, 8), ErrNilByteOrder))
+	assert.True(t, errors.Is(r.ReadPrefixedBytes(&dst, PrefixUint8, -1), ErrNegativeLength))
+	assert.True(t, errors.Is(r.ReadPrefixedBytes(&dst, PrefixWidth(-1), 8), errBadPrefix))
+	r = Reader{}
+	assert.True(t, errors.Is(r.ReadPrefixedBytes(&dst, PrefixUint8, 8), ErrNilSource))
+	assert.True(t, errors.Is(r.ReadPrefixedString(&s, PrefixUint8, 8), ErrNilSource))
+}
+
+func TestReadPrefixedHostileLength(t *testing.T) {
+	// a length within a generous `max` fails as truncated, allocating only
+	// for the bytes which arrive
+	src := make([]byte, binary.MaxVarintLen64+3)
+	n := binary.PutUvarint(src, uint64(maxInt))
+	copy(src[n:], "abc")
+	src = src[:n+3]
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	var e *Error
+	dst := []byte{0xAA}
+	r := NewReaderBytes(src, binary.LittleEndian)
+	err := r.ReadPrefixedBytes(&dst, PrefixUvarint, maxInt)
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, Error{Op: "ReadPrefixedBytes", Offset: int64(n), Requested: maxInt, Got: 3, Err: io.ErrUnexpectedEOF}, *e)
+	assert.Equal(t, []byte{0xAA}, dst)
+	s := "unchanged"
+	r = NewReaderBytes(src, binary.LittleEndian)
+	assert.True(t, errors.Is(r.ReadPrefixedString(&s, PrefixUvarint, maxInt), io.ErrUnexpectedEOF))
+	assert.Equal(t, "unchanged", s)
+	r = NewReaderBytes([]byte{0xFF, 0xFF, 0xFF, 0x7F, 'a'}, binary.BigEndian)
+	assert.True(t, errors.Is(r.ReadPrefixedBytes(&dst, PrefixUint32, maxInt), io.ErrUnexpectedEOF))
+	runtime.ReadMemStats(&after)
+	assert.True(t, after.TotalAlloc-before.TotalAlloc < 4*allocChunk, "allocated %d bytes", after.TotalAlloc-before.TotalAlloc)
+
+	// whereas lengths spanning several steps of growth are read in full
+	long := bytes.Repeat([]byte("0123456789"), allocChunk/2)
+	out := bytes.NewBuffer([]byte{})
+	w := NewWriter(out, binary.LittleEndian)
+	assert.NoError(t, w.WritePrefixedBytes(long, PrefixUint32))
+	assert.NoError(t, w.WritePrefixedBytes(long, PrefixUvarint))
+	r = NewReader(struct{ io.Reader }{out}, binary.LittleEndian)
+	assert.NoError(t, r.ReadPrefixedBytes(&dst, PrefixUint32, maxInt))
+	assert.Equal(t, long, dst)
+	assert.NoError(t, r.ReadPrefixedString(&s, PrefixUvarint, maxInt))
+	assert.Equal(t, string(long), s)
+}
+
+func TestWritePrefixedError(t *testing.T) {
+	t.Parallel()
+	var e *Error
+	w := NewWriter(blackHole, binary.LittleEndian)
+	err := w.WritePrefixedBytes(make([]byte, 256), PrefixUint8)
+	assert.True(t, errors.Is(err, ErrTooLong))
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, 256, e.Requested)
+	assert.True(t, errors.Is(w.WritePrefixedString(strings.Repeat("x", 1<<16), PrefixUint16), ErrTooLong))
+	assert.Equal(t, int64(0), w.GetPosition())
+	assert.NoError(t, w.WritePrefixedBytes(make([]byte, 255), PrefixUint8))
+
+	w.SetMaxVarintLen(1)
+	assert.True(t, errors.Is(w.WritePrefixedString(strings.Repeat("x", 128), PrefixUvarint), ErrTooLong))
+	assert.True(t, errors.Is(w.WritePrefixedString("x", PrefixWidth(4)), errBadPrefix))
+
+	w = NewWriter(blackHole, nil)
+	assert.True(t, errors.Is(w.WritePrefixedBytes(nil, PrefixUint16), ErrNilByteOrder))
+	assert.True(t, errors.Is(w.WritePrefixedString("", PrefixUint32), ErrNilByteOrder))
+	assert.NoError(t, w.WritePrefixedString("x", PrefixUint8))
+
+	w = NewWriter(errRW, binary.LittleEndian)
+	assert.Error(t, w.WritePrefixedBytes([]byte{0x01}, PrefixUint8))
+	assert.Error(t, w.WritePrefixedString("x", PrefixUvarint))
+	w = Writer{}
+	assert.True(t, errors.Is(w.WritePrefixedBytes(nil, PrefixUint8), ErrNilSource))
+	assert.True(t, errors.Is(w.WritePrefixedString("", PrefixUint8), ErrNilSource))
+
+	// a failure part way through a long string reports the whole string
+	w = NewWriter(&limitedWriter{n: 1500}, binary.LittleEndian)
+	err = w.WritePrefixedString(strings.Repeat("x", 3000), PrefixUint16)
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, Error{Op: "WritePrefixedString", Offset: 2, Requested: 3000, Got: 1498, Err: e.Err}, *e)
+}
+
+func TestPrefixedAllocs(t *testing.T) {
+	out := bytes.NewBuffer([]byte{})
+	w := NewWriter(out, binary.LittleEndian)
+	w.WritePrefixedBytes([]byte("0123456789"), PrefixUvarint)
+	r := NewReader(&loopReader{buf: out.Bytes()}, binary.LittleEndian)
+	dst := make([]byte, 0, 16)
+	assert.Equal(t, float64(0), testing.AllocsPerRun(100, func() {
+		if err := r.ReadPrefixedBytes(&dst, PrefixUvarint, 16); err != nil {
+			panic(err)
+		}
+	}))
+	w = NewWriter(blackHole, binary.LittleEndian)
+	long := strings.Repeat("x", 4000)
+	assert.Equal(t, float64(0), testing.AllocsPerRun(100, func() {
+		if err := w.WritePrefixedString(long, PrefixUint16); err != nil {
+			panic(err)
+		}
+	}))
+}
+
+/*
+===============================================================================
+    Strings
+===============================================================================
+*/
+
+func TestReadCString(t *testing.T) {
+	t.Parallel()
+	long := strings.Repeat("abcdefgh", 100)
+	src := append([]byte("hello\x00\x00"+long+"\x00"), 0x01, 0x02)
+	counter := &countingReader{r: bytes.NewReader(src)}
+	bb := NewReader(counter, binary.BigEndian)
+	s := ""
+	assert.NoError(t, bb.ReadCString(&s, 16))
+	assert.Equal(t, "hello", s)
+	assert.Equal(t, int64(6), bb.GetPosition())
+	assert.NoError(t, bb.ReadCString(&s, 16))
+	assert.Equal(t, "", s)
+	assert.NoError(t, bb.ReadCString(&s, len(long)))
+	assert.Equal(t, long, s)
+	// the source is read in chunks, rather than a byte at a time
+	assert.True(t, counter.n < 10, "%d reads", counter.n)
+
+	// bytes read beyond the terminator remain available
+	ui16 := uint16(0)
+	assert.NoError(t, bb.ReadUint16(&ui16))
+	assert.Equal(t, uint16(0x0102), ui16)
+	assert.Equal(t, int64(len(src)), bb.GetPosition())
+
+	// previously peeked bytes are scanned first
+	bb = NewReaderBytes([]byte("abc\x00def\x00"), binary.BigEndian)
+	assert.NoError(t, bb.Peek(make([]byte, 6)))
+	assert.NoError(t, bb.ReadCString(&s, 8))
+	assert.Equal(t, "abc", s)
+	assert.NoError(t, bb.ReadCString(&s, 8))
+	assert.Equal(t, "def", s)
+	assert.Equal(t, int64(8), bb.GetPosition())
+
+	// the terminator may immediately follow `max` bytes
+	bb = NewReaderBytes([]byte("abc\x00"), binary.BigEndian)
+	assert.NoError(t, bb.ReadCString(&s, 3))
+	assert.Equal(t, "abc", s)
+
+	// the largest `max` imposes no limit, including beyond the buffer
+	bb = NewReaderBytes(append([]byte("abc\x00"+long+"\x00"), 0x01), binary.BigEndian)
+	bb.SetReadAheadLimit(64)
+	assert.NoError(t, bb.ReadCString(&s, maxInt))
+	assert.Equal(t, "abc", s)
+	assert.NoError(t, bb.ReadCString(&s, maxInt))
+	assert.Equal(t, long, s)
+	assert.True(t, errors.Is(bb.ReadCString(&s, maxInt), io.ErrUnexpectedEOF))
+}
+
+func TestReadCStringError(t *testing.T) {
+	t.Parallel()
+	var e *Error
+	s := "unchanged"
+	// too long; the reader is not advanced
+	bb := NewReaderBytes([]byte("abcd\x00"), binary.BigEndian)
+	err := bb.ReadCString(&s, 3)
+	assert.True(t, errors.Is(err, ErrTooLong))
+	assert.Equal(t, "unchanged", s)
+	assert.Equal(t, int64(0), bb.GetPosition())
+	tmp := make([]byte, 5)
+	assert.NoError(t, bb.ReadBytes(tmp))
+	assert.Equal(t, []byte("abcd\x00"), tmp)
+
+	// unterminated
+	bb = NewReaderBytes([]byte("abcd"), binary.BigEndian)
+	assert.NoError(t, bb.Discard(1))
+	err = bb.ReadCString(&s, 16)
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, Error{Op: "ReadCString", Offset: 1, Err: io.ErrUnexpectedEOF}, *e)
+	bb = NewReaderBytes([]byte{}, binary.BigEndian)
+	assert.True(t, errors.Is(bb.ReadCString(&s, 16), io.EOF))
+	assert.Equal(t, "unchanged", s)
+
+	// container bounds
+	bb = NewReaderBytes([]byte("abcd\x00"), binary.BigEndian)
+	sub, err := bb.SubReader(3)
+	assert.NoError(t, err)
+	assert.True(t, errors.Is(sub.ReadCString(&s, 16), ErrExceededContainer))
+
+	bb = NewReader(errRW, binary.BigEndian)
+	assert.Error(t, bb.ReadCString(&s, 16))
+	bb = NewReader(negRW, binary.BigEndian)
+	assert.True(t, errors.Is(bb.ReadCString(&s, 16), io.ErrNoProgress))
+	assert.True(t, errors.Is(bb.ReadCString(&s, -1), ErrNegativeLength))
+	bb = Reader{}
+	assert.True(t, errors.Is(bb.ReadCString(&s, 16), ErrNilSource))
+}
+
+func TestReadCStringLong(t *testing.T) {
+	t.Parallel()
+	long := strings.Repeat("abcdefg", 100)
+	src := append([]byte(long), 0, 'x')
+	bb := NewReaderBytes(src, nil)
+	bb.SetReadAheadLimit(64)
+	s := ""
+	assert.NoError(t, bb.ReadCString(&s, 1000))
+	assert.Equal(t, long, s)
+	assert.True(t, cap(bb.buf) <= 64)
+	var c byte
+	assert.NoError(t, bb.ReadByte(&c))
+	assert.Equal(t, byte('x'), c)
+
+	// the bytes scanned are consumed if the string is too long
+	bb = NewReaderBytes(src, nil)
+	bb.SetReadAheadLimit(64)
+	var e *Error
+	err := bb.ReadCString(&s, 699)
+	assert.True(t, errors.Is(err, ErrTooLong))
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, int64(0), e.Offset)
+	assert.True(t, bb.GetPosition() > 0)
+	bb = NewReaderBytes(src[:600], nil)
+	bb.SetReadAheadLimit(64)
+	assert.True(t, errors.Is(bb.ReadCString(&s, 1000), io.ErrUnexpectedEOF))
+}
+
+func TestReadFixedString(t *testing.T) {
+	t.Parallel()
+	src := []byte("ab\x00\x00cd  ef \x00 \x00gh")
+	bb := NewReaderBytes(src, binary.BigEndian)
+	s := ""
+	assert.NoError(t, bb.ReadFixedString(&s, 4, TrimNulls))
+	assert.Equal(t, "ab", s)
+	assert.NoError(t, bb.ReadFixedString(&s, 4, TrimSpaces))
+	assert.Equal(t, "cd", s)
+	assert.NoError(t, bb.ReadFixedString(&s, 6, TrimNulls|TrimSpaces))
+	assert.Equal(t, "ef", s)
+	assert.NoError(t, bb.ReadFixedString(&s, 2, 0))
+	assert.Equal(t, "gh", s)
+	assert.NoError(t, bb.ReadFixedString(&s, 0, TrimNulls))
+	assert.Equal(t, "", s)
+
+	long := strings.Repeat("x", 2000)
+	bb = NewReaderBytes([]byte(long+"\x00\x00"), binary.BigEndian)
+	assert.NoError(t, bb.ReadFixedString(&s, 2002, TrimNulls))
+	assert.Equal(t, long, s)
+
+	s = "unchanged"
+	bb = NewReaderBytes([]byte("abc"), binary.BigEndian)
+	assert.True(t, errors.Is(bb.ReadFixedString(&s, 4, 0), io.ErrUnexpectedEOF))
+	// a hostile length fails as truncated, rather than being allocated
+	bb = NewReaderBytes([]byte("abc"), binary.BigEndian)
+	assert.True(t, errors.Is(bb.ReadFixedString(&s, maxInt, 0), io.ErrUnexpectedEOF))
+	assert.True(t, errors.Is(bb.ReadFixedString(&s, -1, 0), ErrNegativeLength))
+	assert.Equal(t, "unchanged", s)
+	bb = Reader{}
+	assert.True(t, errors.Is(bb.ReadFixedString(&s, 1, 0), ErrNilSource))
+}
+
+func TestWriteCString(t *testing.T) {
+	t.Parallel()
+	out := bytes.NewBuffer([]byte{})
+	bw := NewWriter(out, binary.BigEndian)
+	assert.NoError(t, bw.WriteCString("hello"))
+	assert.NoError(t, bw.WriteCString(""))
+	assert.Equal(t, []byte("hello\x00\x00"), out.Bytes())
+	assert.Equal(t, int64(7), bw.GetPosition())
+
+	// round trip
+	bb := NewReaderBytes(out.Bytes(), binary.BigEndian)
+	s := ""
+	assert.NoError(t, bb.ReadCString(&s, 5))
+	assert.Equal(t, "hello", s)
+
+	assert.True(t, errors.Is(bw.WriteCString("a\x00b"), ErrInvalidEncoding))
+	assert.Equal(t, int64(7), bw.GetPosition())
+	bw = NewWriter(errRW, binary.BigEndian)
+	assert.Error(t, bw.WriteCString("a"))
+	bw = NewWriter(&limitedWriter{n: 1}, binary.BigEndian)
+	assert.Error(t, bw.WriteCString("a"))
+	bw = Writer{}
+	assert.True(t, errors.Is(bw.WriteCString("a"), ErrNilSource))
+}
+
+func TestWriteFixedString(t *testing.T) {
+	t.Parallel()
+	out := bytes.NewBuffer([]byte{})
+	bw := NewWriter(out, binary.BigEndian)
+	assert.NoError(t, bw.WriteFixedString("ab", 4, 0))
+	assert.NoError(t, bw.WriteFixedString("cd", 4, ' '))
+	assert.NoError(t, bw.WriteFixedString("ef", 2, ' '))
+	assert.NoError(t, bw.WriteFixedString("", 0, ' '))
+	assert.Equal(t, []byte("ab\x00\x00cd  ef"), out.Bytes())
+
+	// padding longer than the temporary buffer
+	out.Reset()
+	assert.NoError(t, bw.WriteFixedString("x", 3000, '-'))
+	assert.Equal(t, "x"+strings.Repeat("-", 2999), out.String())
+
+	var e *Error
+	err := bw.WriteFixedString("abc", 2, 0)
+	assert.True(t, errors.Is(err, ErrTooLong))
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, 3, e.Requested)
+	assert.True(t, errors.Is(bw.WriteFixedString("", -1, 0), ErrNegativeLength))
+
+	bw = NewWriter(&limitedWriter{n: 1500}, binary.BigEndian)
+	err = bw.WriteFixedString("abc", 2000, ' ')
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, Error{Op: "WriteFixedString", Offset: 3, Requested: 1997, Got: 1497, Err: e.Err}, *e)
+	bw = NewWriter(errRW, binary.BigEndian)
+	assert.Error(t, bw.WriteFixedString("abc", 4, 0))
+	bw = Writer{}
+	assert.True(t, errors.Is(bw.WriteFixedString("a", 1, 0), ErrNilSource))
+}
+
+func TestStringsAllocs(t *testing.T) {
+	bw := NewWriter(blackHole, binary.BigEndian)
+	assert.Equal(t, float64(0), testing.AllocsPerRun(100, func() {
+		if err := bw.WriteFixedString("hello", 2000, ' '); err != nil {
+			panic(err)
+		}
+		if err := bw.WriteCString("hello"); err != nil {
+			panic(err)
+		}
+	}))
+	// only the string itself is allocated
+	bb := NewReader(&loopReader{buf: []byte("hello\x00")}, binary.BigEndian)
+	s := ""
+	assert.Equal(t, float64(1), testing.AllocsPerRun(100, func() {
+		if err := bb.ReadCString(&s, 16); err != nil {
+			panic(err)
+		}
+	}))
+}
+
+/*
+===============================================================================
+    Text
+===============================================================================
+*/
+
+func TestUTF16(t *testing.T) {
+	t.Parallel()
+	text := "héllo, 世界 🎉"
+	for _, bo := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		out := bytes.NewBuffer([]byte{})
+		bw := NewWriter(out, bo)
+		assert.NoError(t, bw.WriteUTF16(text, nil, false))
+		// the emoji requires a surrogate pair
+		n := len(utf16.Encode([]rune(text)))
+		assert.Equal(t, n*2, out.Len())
+
+		bb := NewReaderBytes(out.Bytes(), bo)
+		s := ""
+		assert.NoError(t, bb.ReadUTF16(&s, n, nil))
+		assert.Equal(t, text, s)
+		assert.Equal(t, int64(n*2), bb.GetPosition())
+	}
+
+	// exact encoding
+	out := bytes.NewBuffer([]byte{})
+	bw := NewWriter(out, binary.LittleEndian)
+	assert.NoError(t, bw.WriteUTF16("A🎉", binary.BigEndian, false))
+	assert.Equal(t, []byte{0x00, 0x41, 0xD8, 0x3C, 0xDF, 0x89}, out.Bytes())
+	assert.Equal(t, binary.LittleEndian, bw.GetByteOrder())
+
+	// per-call byte order overrides the current one
+	bb := NewReaderBytes(out.Bytes(), binary.LittleEndian)
+	s := ""
+	assert.NoError(t, bb.ReadUTF16(&s, 3, binary.BigEndian))
+	assert.Equal(t, "A🎉", s)
+
+	// strings longer than the temporary buffer
+	long := strings.Repeat("😀é", 300)
+	out.Reset()
+	assert.NoError(t, bw.WriteUTF16(long, nil, true))
+	bb = NewReaderBytes(out.Bytes(), binary.LittleEndian)
+	assert.NoError(t, bb.ReadUTF16(&s, out.Len()/2, nil))
+	assert.Equal(t, long, s)
+
+	// invalid UTF-8 is replaced
+	out.Reset()
+	assert.NoError(t, bw.WriteUTF16("a\xffb", binary.BigEndian, false))
+	assert.Equal(t, []byte{0x00, 'a', 0xFF, 0xFD, 0x00, 'b'}, out.Bytes())
+}
+
+func TestUTF16BOM(t *testing.T) {
+	t.Parallel()
+	for _, bo := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		out := bytes.NewBuffer([]byte{})
+		bw := NewWriter(out, nil)
+		assert.NoError(t, bw.WriteUTF16("hi", bo, true))
+
+		// the BOM overrides the byte order, and is removed
+		for _, readBO := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+			bb := NewReaderBytes(out.Bytes(), readBO)
+			s := ""
+			assert.NoError(t, bb.ReadUTF16(&s, 3, nil))
+			assert.Equal(t, "hi", s)
+		}
+	}
+	bb := NewReaderBytes([]byte{0xFF, 0xFE}, binary.BigEndian)
+	s := "unchanged"
+	assert.NoError(t, bb.ReadUTF16(&s, 1, nil))
+	assert.Equal(t, "", s)
+}
+
+func TestUTF16Surrogates(t *testing.T) {
+	t.Parallel()
+	s := ""
+	for _, c := range []struct {
+		units    []uint16
+		expected string
+	}{
+		{[]uint16{0xD83C, 0xDF89}, "🎉"},
+		{[]uint16{0xD83C}, "�"},                   // unpaired high surrogate at end
+		{[]uint16{0xD83C, 0x0041}, "�A"},          // high surrogate followed by non-surrogate
+		{[]uint16{0xDF89, 0xD83C}, "��"},          // reversed pair
+		{[]uint16{0x0041, 0xDF89, 0x0042}, "A�B"}, // lone low surrogate
+	} {
+		buf := make([]byte, len(c.units)*2)
+		for i, u := range c.units {
+			binary.BigEndian.PutUint16(buf[i*2:], u)
+		}
+		bb := NewReaderBytes(buf, binary.BigEndian)
+		assert.NoError(t, bb.ReadUTF16(&s, len(c.units), nil))
+		assert.Equal(t, c.expected, s, "%04X", c.units)
+	}
+}
+
+func TestUTF16Error(t *testing.T) {
+	t.Parallel()
+	s := "unchanged"
+	bb := NewReaderBytes([]byte{0x00, 0x41, 0x00}, nil)
+	assert.True(t, errors.Is(bb.ReadUTF16(&s, 1, nil), ErrNilByteOrder))
+	assert.True(t, errors.Is(bb.ReadUTF16(&s, -1, binary.BigEndian), ErrNegativeLength))
+	assert.True(t, errors.Is(bb.ReadUTF16(&s, 2, binary.BigEndian), io.ErrUnexpectedEOF))
+	assert.Equal(t, "unchanged", s)
+	bb = Reader{}
+	assert.True(t, errors.Is(bb.ReadUTF16(&s, 1, binary.BigEndian), ErrNilSource))
+
+	bw := NewWriter(blackHole, nil)
+	assert.True(t, errors.Is(bw.WriteUTF16("a", nil, false), ErrNilByteOrder))
+	bw = NewWriter(errRW, binary.BigEndian)
+	assert.Error(t, bw.WriteUTF16("a", nil, false))
+	bw = NewWriter(&limitedWriter{n: 100}, binary.BigEndian)
+	assert.Error(t, bw.WriteUTF16(strings.Repeat("a", 1000), nil, false))
+	bw = Writer{}
+	assert.True(t, errors.Is(bw.WriteUTF16("a", binary.BigEndian, false), ErrNilSource))
+}
+
+func TestLatin1(t *testing.T) {
+	t.Parallel()
+	out := bytes.NewBuffer([]byte{})
+	bw := NewWriter(out, nil)
+	assert.NoError(t, bw.WriteLatin1("plain"))
+	assert.NoError(t, bw.WriteLatin1("café ÿ"))
+	assert.Equal(t, []byte("plaincaf\xe9 \xff"), out.Bytes())
+
+	bb := NewReaderBytes(out.Bytes(), nil)
+	s := ""
+	assert.NoError(t, bb.ReadLatin1(&s, 5))
+	assert.Equal(t, "plain", s)
+	assert.NoError(t, bb.ReadLatin1(&s, 6))
+	assert.Equal(t, "café ÿ", s)
+
+	// strings longer than the temporary buffer
+	long := strings.Repeat("é", 1500)
+	out.Reset()
+	assert.NoError(t, bw.WriteLatin1(long))
+	assert.Equal(t, 1500, out.Len())
+	bb = NewReaderBytes(out.Bytes(), nil)
+	assert.NoError(t, bb.ReadLatin1(&s, 1500))
+	assert.Equal(t, long, s)
+}
+
+func TestLatin1Error(t *testing.T) {
+	t.Parallel()
+	bw := NewWriter(blackHole, nil)
+	assert.True(t, errors.Is(bw.WriteLatin1("a€"), ErrInvalidEncoding))
+	assert.True(t, errors.Is(bw.WriteLatin1("é\xff"), ErrInvalidEncoding))
+	assert.Equal(t, int64(0), bw.GetPosition())
+	bw = NewWriter(errRW, nil)
+	assert.Error(t, bw.WriteLatin1("é"))
+	assert.Error(t, bw.WriteLatin1("aé"))
+	bw = NewWriter(&limitedWriter{n: 1000}, nil)
+	assert.Error(t, bw.WriteLatin1(strings.Repeat("é", 1500)))
+	bw = Writer{}
+	assert.True(t, errors.Is(bw.WriteLatin1("a"), ErrNilSource))
+
+	s := "unchanged"
+	bb := NewReaderBytes([]byte{0xE9}, nil)
+	assert.True(t, errors.Is(bb.ReadLatin1(&s, 2), io.ErrUnexpectedEOF))
+	assert.True(t, errors.Is(bb.ReadLatin1(&s, -1), ErrNegativeLength))
+	assert.Equal(t, "unchanged", s)
+	bb = Reader{}
+	assert.True(t, errors.Is(bb.ReadLatin1(&s, 1), ErrNilSource))
+}
+
+func TestASCII(t *testing.T) {
+	t.Parallel()
+	out := bytes.NewBuffer([]byte{})
+	bw := NewWriter(out, nil)
+	assert.NoError(t, bw.WriteASCII("hello\x7f"))
+	assert.True(t, errors.Is(bw.WriteASCII("héllo"), ErrInvalidEncoding))
+	assert.Equal(t, []byte("hello\x7f"), out.Bytes())
+	bw = Writer{}
+	assert.True(t, errors.Is(bw.WriteASCII("a"), ErrNilSource))
+
+	bb := NewReaderBytes([]byte("hello\x7fab\x80c"), nil)
+	s := ""
+	assert.NoError(t, bb.ReadASCII(&s, 6))
+	assert.Equal(t, "hello\x7f", s)
+	var e *Error
+	err := bb.ReadASCII(&s, 4)
+	assert.True(t, errors.Is(err, ErrInvalidEncoding))
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, int64(8), e.Offset)
+	assert.Equal(t, "hello\x7f", s)
+	assert.Equal(t, int64(10), bb.GetPosition())
+
+	assert.True(t, errors.Is(bb.ReadASCII(&s, 1), io.EOF))
+	assert.True(t, errors.Is(bb.ReadASCII(&s, -1), ErrNegativeLength))
+	bb = NewReaderBytes(bytes.Repeat([]byte("a"), 2000), nil)
+	assert.NoError(t, bb.ReadASCII(&s, 2000))
+	assert.Equal(t, strings.Repeat("a", 2000), s)
+	bb = Reader{}
+	assert.True(t, errors.Is(bb.ReadASCII(&s, 1), ErrNilSource))
+}
+
+func TestReadTextHostileLength(t *testing.T) {
+	// a count of code units whose size in bytes overflows is rejected up front
+	s := "unchanged"
+	var e *Error
+	bb := NewReaderBytes([]byte("abc"), binary.BigEndian)
+	err := bb.ReadUTF16(&s, maxInt/2+1, nil)
+	assert.True(t, errors.Is(err, ErrTooLong))
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, Error{Op: "ReadUTF16", Offset: 0, Requested: maxInt/2 + 1, Err: ErrTooLong}, *e)
+	assert.Equal(t, int64(0), bb.GetPosition())
+
+	// while lengths that fit fail as truncated, allocating only for the bytes
+	// which arrive
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	assert.True(t, errors.Is(bb.ReadUTF16(&s, maxInt/2, nil), io.ErrUnexpectedEOF))
+	bb = NewReaderBytes([]byte("abc"), nil)
+	assert.True(t, errors.Is(bb.ReadLatin1(&s, maxInt), io.ErrUnexpectedEOF))
+	bb = NewReaderBytes([]byte("abc"), nil)
+	err = bb.ReadASCII(&s, maxInt)
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, Error{Op: "ReadASCII", Offset: 0, Requested: maxInt, Got: 3, Err: io.ErrUnexpectedEOF}, *e)
+	runtime.ReadMemStats(&after)
+	assert.True(t, after.TotalAlloc-before.TotalAlloc < 4*allocChunk, "allocated %d bytes", after.TotalAlloc-before.TotalAlloc)
+	assert.Equal(t, "unchanged", s)
+
+	// whereas lengths spanning several steps of growth are read in full
+	long := strings.Repeat("0123456789", allocChunk/2)
+	out := bytes.NewBuffer([]byte{})
+	bw := NewWriter(out, binary.LittleEndian)
+	assert.NoError(t, bw.WriteUTF16(long, nil, false))
+	assert.NoError(t, bw.WriteLatin1(long))
+	assert.NoError(t, bw.WriteASCII(long))
+	bb = NewReader(struct{ io.Reader }{out}, binary.LittleEndian)
+	assert.NoError(t, bb.ReadUTF16(&s, len(long), nil))
+	assert.Equal(t, long, s)
+	assert.NoError(t, bb.ReadLatin1(&s, len(long)))
+	assert.Equal(t, long, s)
+	assert.NoError(t, bb.ReadASCII(&s, len(long)))
+	assert.Equal(t, long, s)
+}
+
+/*
+===============================================================================
+    Alignment
+===============================================================================
+*/
+
+func TestAlign(t *testing.T) {
+	t.Parallel()
+	out := bytes.NewBuffer([]byte{})
+	bw := NewWriter(out, binary.BigEndian)
+	assert.NoError(t, bw.Align(4)) // already aligned
+	assert.NoError(t, bw.WriteByte(1))
+	assert.NoError(t, bw.Align(4))
+	assert.Equal(t, int64(4), bw.GetPosition())
+	assert.NoError(t, bw.WriteUint16(2))
+	assert.NoError(t, bw.AlignPad(8, 0xAA))
+	assert.NoError(t, bw.WriteByte(3))
+	assert.NoError(t, bw.Align(1))
+	assert.NoError(t, bw.Align(16))
+	assert.Equal(t, []byte{
+		1, 0, 0, 0,
+		0, 2, 0xAA, 0xAA,
+		3, 0, 0, 0, 0, 0, 0, 0,
+	}, out.Bytes())
+
+	bb := NewReaderBytes(out.Bytes(), binary.BigEndian)
+	var v byte
+	var u16 uint16
+	assert.NoError(t, bb.ReadByte(&v))
+	assert.NoError(t, bb.AlignZero(4))
+	assert.Equal(t, int64(4), bb.GetPosition())
+	assert.NoError(t, bb.ReadUint16(&u16))
+	assert.NoError(t, bb.Align(8))
+	assert.NoError(t, bb.ReadByte(&v))
+	assert.Equal(t, byte(3), v)
+	assert.NoError(t, bb.AlignZero(16))
+	assert.Equal(t, int64(16), bb.GetPosition())
+	assert.NoError(t, bb.Align(16))
+
+	// alignments larger than the temporary buffer
+	out.Reset()
+	bw.Reset(out, nil)
+	assert.NoError(t, bw.WriteByte(1))
+	assert.NoError(t, bw.AlignPad(4096, 0xFF))
+	assert.Equal(t, 4096, out.Len())
+	out.Reset()
+	bw.Reset(out, nil)
+	assert.NoError(t, bw.WriteByte(1))
+	assert.NoError(t, bw.Align(4096))
+	assert.Equal(t, 4096, out.Len())
+	bb = NewReaderBytes(out.Bytes(), nil)
+	assert.NoError(t, bb.ReadByte(&v))
+	assert.NoError(t, bb.AlignZero(4096))
+	assert.Equal(t, int64(4096), bb.GetPosition())
+}
+
+func TestAlignBase(t *testing.T) {
+	t.Parallel()
+	out := bytes.NewBuffer([]byte{})
+	bw := NewWriter(out, nil)
+	assert.NoError(t, bw.ZeroFill(3))
+	bw.SetAlignBase(3)
+	assert.Equal(t, int64(3), bw.GetAlignBase())
+	assert.NoError(t, bw.Align(4)) // aligned relative to the base
+	assert.NoError(t, bw.WriteByte(1))
+	assert.NoError(t, bw.Align(4))
+	assert.Equal(t, int64(7), bw.GetPosition())
+	bw.SetAlignBase(10) // base ahead of the position
+	assert.NoError(t, bw.Align(4))
+	assert.Equal(t, int64(10), bw.GetPosition())
+	bw.Reset(out, nil)
+	assert.Equal(t, int64(0), bw.GetAlignBase())
+
+	bb := NewReaderBytes(make([]byte, 32), nil)
+	assert.NoError(t, bb.Discard(5))
+	bb.SetAlignBase(1)
+	assert.NoError(t, bb.Align(4))
+	assert.Equal(t, int64(5), bb.GetPosition())
+	assert.NoError(t, bb.Discard(1))
+	assert.NoError(t, bb.AlignZero(4))
+	assert.Equal(t, int64(9), bb.GetPosition())
+
+	// sub-readers align relative to their container
+	sub, err := bb.SubReader(16)
+	assert.NoError(t, err)
+	assert.NoError(t, sub.Discard(1))
+	assert.NoError(t, sub.Align(8))
+	assert.Equal(t, int64(8), sub.GetPosition())
+	assert.Equal(t, int64(17), sub.GetAbsolutePosition())
+	bb.Reset(bytes.NewReader(nil), nil)
+	assert.Equal(t, int64(0), bb.GetAlignBase())
+}
+
+func TestAlignError(t *testing.T) {
+	t.Parallel()
+	bb := NewReaderBytes([]byte{1, 0, 0, 7, 0, 9, 0, 0, 1}, nil)
+	var v byte
+	assert.NoError(t, bb.ReadByte(&v))
+	for _, n := range []int64{0, -4} {
+		assert.True(t, errors.Is(bb.Align(n), errBadAlignment))
+		assert.True(t, errors.Is(bb.AlignZero(n), errBadAlignment))
+	}
+	var e *Error
+	err := bb.AlignZero(8)
+	assert.True(t, errors.Is(err, ErrNonZeroPadding))
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, int64(3), e.Offset)
+	assert.Equal(t, int64(8), bb.GetPosition()) // consumed regardless
+	assert.NoError(t, bb.ReadByte(&v))
+	assert.True(t, errors.Is(bb.Align(4), io.EOF))
+	assert.True(t, errors.Is(bb.AlignZero(4), io.EOF))
+	bb = Reader{}
+	assert.True(t, errors.Is(bb.Align(4), ErrNilSource))
+	assert.True(t, errors.Is(bb.AlignZero(4), ErrNilSource))
+
+	bw := NewWriter(blackHole, nil)
+	assert.True(t, errors.Is(bw.Align(0), errBadAlignment))
+	assert.True(t, errors.Is(bw.AlignPad(-1, 0xFF), errBadAlignment))
+	bw = NewWriter(&limitedWriter{n: 2}, nil)
+	assert.NoError(t, bw.WriteByte(1))
+	err = bw.AlignPad(4, 0xFF)
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, "AlignPad", e.Op)
+	assert.Equal(t, 3, e.Requested)
+	bw = Writer{}
+	assert.True(t, errors.Is(bw.Align(4), ErrNilSource))
+	assert.True(t, errors.Is(bw.AlignPad(4, 1), ErrNilSource))
+}
+
+/*
+===============================================================================
+    Reservations
+===============================================================================
+*/
+
+func TestReserve(t *testing.T) {
+	t.Parallel()
+	expected := []byte{
+		0x00, 0x00, 0x00, 0x0B, // length of the following
+		0x01,       // version
+		0x00, 0x03, // count
+		0xAA, 0xBB, 0xCC,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0F, // total
+		0xFF,
+	}
+	for _, dest := range []io.Writer{&bytes.Buffer{}, &seekBuffer{}, unseekable{&bytes.Buffer{}}} {
+		bw := NewWriter(dest, binary.BigEndian)
+		length, err := bw.Reserve(4)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), length.Offset())
+		assert.Equal(t, 4, length.Size())
+		assert.NoError(t, bw.WriteByte(1))
+		count, err := bw.Reserve(2)
+		assert.NoError(t, err)
+		_, err = bw.Write([]byte{0xAA, 0xBB})
+		assert.NoError(t, err)
+		assert.NoError(t, bw.WriteByte(0xCC))
+		total, err := bw.Reserve(8)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(10), total.Offset())
+		assert.NoError(t, count.PatchUint16(3))
+		assert.NoError(t, length.PatchUint32(uint32(bw.GetPosition()-4-3)))
+		assert.NoError(t, total.PatchUint64(uint64(bw.GetPosition()-3)))
+		assert.NoError(t, bw.WriteByte(0xFF))
+		assert.Equal(t, int64(len(expected)), bw.GetPosition())
+
+		var out []byte
+		switch d := dest.(type) {
+		case *bytes.Buffer:
+			out = d.Bytes()
+		case *seekBuffer:
+			out = d.buf
+		case unseekable:
+			out = d.Writer.(*bytes.Buffer).Bytes()
+		}
+		assert.Equal(t, expected, out, "%T", dest)
+	}
+}
+
+func TestReserveHeld(t *testing.T) {
+	t.Parallel()
+	out := bytes.NewBuffer([]byte{})
+	bw := NewWriter(out, binary.LittleEndian)
+	assert.NoError(t, bw.WriteByte(1))
+	assert.Equal(t, 1, out.Len())
+
+	// writes are held until every reservation is patched
+	a, err := bw.Reserve(1)
+	assert.NoError(t, err)
+	b, err := bw.Reserve(3)
+	assert.NoError(t, err)
+	assert.NoError(t, bw.ZeroFill(2000))
+	assert.NoError(t, bw.WriteUint16(0x0102))
+	assert.Equal(t, 1, out.Len())
+	assert.NoError(t, a.PatchUint8(9))
+	assert.Equal(t, 1, out.Len())
+	assert.NoError(t, b.PatchBytes([]byte{7, 8, 9}))
+	assert.Equal(t, 2007, out.Len())
+	assert.Equal(t, []byte{1, 9, 7, 8, 9}, out.Bytes()[:5])
+	assert.Equal(t, []byte{2, 1}, out.Bytes()[2005:])
+
+	// and written directly thereafter
+	assert.NoError(t, bw.WriteByte(3))
+	assert.Equal(t, 2008, out.Len())
+
+	// zero-sized reservations hold writes too
+	c, err := bw.Reserve(0)
+	assert.NoError(t, err)
+	assert.NoError(t, bw.WriteByte(4))
+	assert.Equal(t, 2008, out.Len())
+	assert.NoError(t, c.PatchBytes(nil))
+	assert.Equal(t, 2009, out.Len())
+
+	// a held reservation is discarded by `Reset`
+	d, err := bw.Reserve(1)
+	assert.NoError(t, err)
+	out.Reset()
+	bw.Reset(out, binary.LittleEndian)
+	assert.True(t, errors.Is(d.PatchUint8(1), errStaleReserve))
+	assert.NoError(t, bw.WriteByte(5))
+	assert.Equal(t, []byte{5}, out.Bytes())
+}
+
+func TestReserveError(t *testing.T) {
+	t.Parallel()
+	bw := Writer{}
+	_, err := bw.Reserve(4)
+	assert.True(t, errors.Is(err, ErrNilSource))
+	bw = NewWriter(blackHole, nil)
+	_, err = bw.Reserve(-1)
+	assert.True(t, errors.Is(err, ErrNegativeLength))
+
+	for _, dest := range []io.Writer{&bytes.Buffer{}, &seekBuffer{}} {
+		bw = NewWriter(dest, nil)
+		r, err := bw.Reserve(4)
+		assert.NoError(t, err)
+		assert.True(t, errors.Is(r.PatchUint32(1), ErrNilByteOrder))
+		assert.True(t, errors.Is(r.PatchUint16(1), ErrNilByteOrder))
+		assert.True(t, errors.Is(r.PatchUint64(1), ErrNilByteOrder))
+		bw.SetByteOrder(binary.BigEndian)
+		assert.True(t, errors.Is(r.PatchUint16(1), errPatchSize))
+		assert.True(t, errors.Is(r.PatchUint8(1), errPatchSize))
+		assert.True(t, errors.Is(r.PatchBytes(make([]byte, 5)), errPatchSize))
+		assert.NoError(t, r.PatchUint32(1))
+		assert.True(t, errors.Is(r.PatchUint32(1), errStaleReserve))
+	}
+
+	// failure to write the held bytes is reported by the final patch
+	bw = NewWriter(&limitedWriter{n: 2}, nil)
+	r, err := bw.Reserve(4)
+	assert.NoError(t, err)
+	var e *Error
+	err = r.PatchBytes([]byte{1, 2, 3, 4})
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, "PatchBytes", e.Op)
+	assert.Equal(t, int64(2), e.Offset) // of the first unwritten byte
+	assert.Error(t, bw.WriteByte(1))
+
+	// and seek failures by the patch which caused them
+	sb := &seekBuffer{}
+	bw = NewWriter(sb, nil)
+	r, err = bw.Reserve(1)
+	assert.NoError(t, err)
+	sb.failSeek = true
+	assert.Error(t, r.PatchUint8(1))
+	sb.failSeek = false
+	assert.NoError(t, r.PatchUint8(1))
+}
+
+/*
+===============================================================================
+    Buffered Writer
+===============================================================================
+*/
+
+func TestBufferedWriter(t *testing.T) {
+	t.Parallel()
+	out := &countingWriter{w: &bytes.Buffer{}}
+	bw := NewBufferedWriter(out, binary.BigEndian, 8)
+	assert.NoError(t, bw.WriteUint32(1))
+	assert.NoError(t, bw.WriteUint16(2))
+	assert.Equal(t, int64(6), bw.GetPosition())
+	assert.Equal(t, 6, bw.Buffered())
+	assert.Equal(t, 0, out.n)
+
+	// the buffer is flushed when full
+	assert.NoError(t, bw.WriteUint32(3))
+	assert.Equal(t, 1, out.n)
+	assert.Equal(t, 4, bw.Buffered())
+	_, err := bw.Write([]byte{4, 5, 6, 7})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, out.n)
+	assert.Equal(t, 8, bw.Buffered())
+
+	// writes larger than the buffer are passed directly
+	assert.NoError(t, bw.WriteBytes(bytes.Repeat([]byte{8}, 9)))
+	assert.Equal(t, 3, out.n)
+	assert.Equal(t, 0, bw.Buffered())
+	assert.NoError(t, bw.WriteByte(9))
+	assert.NoError(t, bw.Flush())
+	assert.NoError(t, bw.Flush())
+	assert.Equal(t, 4, out.n)
+	assert.Equal(t, int64(24), bw.GetPosition())
+	assert.Equal(t, []byte{
+		0, 0, 0, 1, 0, 2, 0, 0, 0, 3, 4, 5, 6, 7,
+		8, 8, 8, 8, 8, 8, 8, 8, 8, 9,
+	}, out.w.(*bytes.Buffer).Bytes())
+
+	// unflushed writes are discarded by `Reset`
+	assert.NoError(t, bw.WriteByte(10))
+	dest := &bytes.Buffer{}
+	bw.Reset(dest, nil)
+	assert.Equal(t, 0, bw.Buffered())
+	assert.NoError(t, bw.WriteByte(11))
+	assert.Equal(t, 0, dest.Len())
+	assert.NoError(t, bw.Flush())
+	assert.Equal(t, []byte{11}, dest.Bytes())
+
+	bw = NewBufferedWriter(dest, nil, 0)
+	assert.Equal(t, DefaultBufferSize, cap(bw.buf))
+}
+
+func TestBufferedWriterReserve(t *testing.T) {
+	t.Parallel()
+	for _, dest := range []io.Writer{&bytes.Buffer{}, &seekBuffer{}} {
+		bw := NewBufferedWriter(dest, binary.LittleEndian, 4)
+		assert.NoError(t, bw.WriteByte(1))
+		r, err := bw.Reserve(2)
+		assert.NoError(t, err)
+		assert.NoError(t, bw.WriteBytes([]byte{2, 3, 4, 5, 6}))
+		assert.NoError(t, r.PatchUint16(0x0807))
+		assert.NoError(t, bw.WriteByte(9))
+		assert.NoError(t, bw.Flush())
+		assert.Equal(t, 0, bw.Buffered())
+
+		var out []byte
+		switch d := dest.(type) {
+		case *bytes.Buffer:
+			out = d.Bytes()
+		case *seekBuffer:
+			out = d.buf
+		}
+		assert.Equal(t, []byte{1, 7, 8, 2, 3, 4, 5, 6, 9}, out, "%T", dest)
+	}
+
+	// held bytes count as buffered, and are not flushed until patched
+	dest := &bytes.Buffer{}
+	bw := NewBufferedWriter(dest, nil, 4)
+	r, err := bw.Reserve(1)
+	assert.NoError(t, err)
+	assert.NoError(t, bw.WriteByte(2))
+	assert.Equal(t, 2, bw.Buffered())
+	assert.NoError(t, bw.Flush())
+	assert.Equal(t, 0, dest.Len())
+	assert.NoError(t, r.PatchUint8(1))
+	assert.NoError(t, bw.Flush())
+	assert.Equal(t, []byte{1, 2}, dest.Bytes())
+}
+
+func TestBufferedWriterError(t *testing.T) {
+	t.Parallel()
+	bw := NewBufferedWriter(nil, nil, 4)
+	assert.True(t, errors.Is(bw.Flush(), ErrNilSource))
+	assert.True(t, errors.Is(bw.WriteByte(1), ErrNilSource))
+
+	// unwritten bytes are retained
+	lw := &limitedWriter{n: 3}
+	bw = NewBufferedWriter(lw, nil, 4)
+	assert.NoError(t, bw.WriteBytes([]byte{1, 2, 3, 4}))
+	assert.Error(t, bw.WriteByte(5))
+	assert.Equal(t, 1, bw.Buffered())
+	var e *Error
+	err := bw.Flush()
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, "Flush", e.Op)
+	assert.Equal(t, int64(3), e.Offset)
+	lw.n = 1
+	assert.NoError(t, bw.Flush())
+	assert.Equal(t, 0, bw.Buffered())
+}
+
+func TestBufferedWriterAllocs(t *testing.T) {
+	bw := NewBufferedWriter(blackHole, binary.LittleEndian, 64)
+	large := make([]byte, 100)
+	allocs := testing.AllocsPerRun(100, func() {
+		for i := 0; i < 100; i++ {
+			_ = bw.WriteUint16(9000)
+			_ = bw.WriteUint64(9000)
+		}
+		_ = bw.WriteBytes(large)
+		_ = bw.Flush()
+	})
+	assert.Equal(t, 0.0, allocs)
+}
+
+/*
+===============================================================================
+    Slices
+===============================================================================
+*/
+
+// customOrder is a `binary.ByteOrder` other than those of the standard
+// library, so that slices cannot be converted with a byte swap.
+type customOrder struct {
+	binary.ByteOrder
+}
+
+func TestSlices(t *testing.T) {
+	t.Parallel()
+	u16 := []uint16{0, 1, 0x0102, math.MaxUint16}
+	u32 := []uint32{0, 1, 0x01020304, math.MaxUint32}
+	u64 := []uint64{0, 1, 0x0102030405060708, math.MaxUint64}
+	f32 := []float32{0, -1.5, math.MaxFloat32, float32(math.Inf(-1))}
+	f64 := []float64{0, -1.5, math.MaxFloat64, math.SmallestNonzeroFloat64}
+	for _, bo := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian, customOrder{binary.BigEndian}} {
+		out := bytes.NewBuffer([]byte{})
+		bw := NewWriter(out, bo)
+		assert.NoError(t, bw.WriteUint16s(u16))
+		assert.NoError(t, bw.WriteUint32s(u32))
+		assert.NoError(t, bw.WriteUint64s(u64))
+		assert.NoError(t, bw.WriteFloat32s(f32))
+		assert.NoError(t, bw.WriteFloat64s(f64))
+		assert.NoError(t, bw.WriteUint16s(nil))
+		assert.NoError(t, bw.WriteFloat32s(nil))
+		assert.NoError(t, bw.WriteFloat64s(nil))
+		assert.Equal(t, int64(4*(2+4+8+4+8)), bw.GetPosition())
+
+		// identical to writing each value in turn
+		expected := bytes.NewBuffer([]byte{})
+		ew := NewWriter(expected, bo)
+		for i := range u16 {
+			ew.WriteUint16(u16[i])
+		}
+		for i := range u32 {
+			ew.WriteUint32(u32[i])
+		}
+		for i := range u64 {
+			ew.WriteUint64(u64[i])
+		}
+		for i := range f32 {
+			ew.WriteFloat32(f32[i])
+		}
+		for i := range f64 {
+			ew.WriteFloat64(f64[i])
+		}
+		assert.Equal(t, expected.Bytes(), out.Bytes(), "%v", bo)
+
+		bb := NewReaderBytes(out.Bytes(), bo)
+		r16 := make([]uint16, len(u16))
+		r32 := make([]uint32, len(u32))
+		r64 := make([]uint64, len(u64))
+		rf32 := make([]float32, len(f32))
+		rf64 := make([]float64, len(f64))
+		assert.NoError(t, bb.ReadUint16s(r16))
+		assert.NoError(t, bb.ReadUint32s(r32))
+		assert.NoError(t, bb.ReadUint64s(r64))
+		assert.NoError(t, bb.ReadFloat32s(rf32))
+		assert.NoError(t, bb.ReadFloat64s(rf64))
+		assert.NoError(t, bb.ReadUint64s(nil))
+		assert.NoError(t, bb.ReadFloat32s(nil))
+		assert.NoError(t, bb.ReadFloat64s(nil))
+		assert.Equal(t, u16, r16)
+		assert.Equal(t, u32, r32)
+		assert.Equal(t, u64, r64)
+		assert.Equal(t, f32, rf32)
+		assert.Equal(t, f64, rf64)
+		assert.Equal(t, bw.GetPosition(), bb.GetPosition())
+	}
+}
+
+func TestSlicesLarge(t *testing.T) {
+	t.Parallel()
+	src := make([]uint32, 1000)
+	for i := range src {
+		src[i] = uint32(i) * 0x01010101
+	}
+	for _, bo := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		out := bytes.NewBuffer([]byte{})
+		bw := NewWriter(out, bo)
+		assert.NoError(t, bw.WriteUint32s(src))
+		assert.Equal(t, 4000, out.Len())
+		assert.Equal(t, src[999], bo.Uint32(out.Bytes()[3996:]))
+		assert.Equal(t, uint32(0xEAEAEAE7), src[999]) // unmodified
+
+		dst := make([]uint32, 1000)
+		bb := NewReaderBytes(out.Bytes(), bo)
+		assert.NoError(t, bb.ReadUint32s(dst))
+		assert.Equal(t, src, dst)
+	}
+}
+
+func TestSlicesError(t *testing.T) {
+	t.Parallel()
+	bb := Reader{}
+	assert.True(t, errors.Is(bb.ReadUint16s(make([]uint16, 1)), ErrNilSource))
+	assert.True(t, errors.Is(bb.ReadFloat64s(make([]float64, 1)), ErrNilSource))
+	bb = NewReaderBytes(make([]byte, 7), nil)
+	assert.True(t, errors.Is(bb.ReadUint32s(make([]uint32, 1)), ErrNilByteOrder))
+	assert.True(t, errors.Is(bb.ReadUint64s(nil), ErrNilByteOrder))
+	bb.SetByteOrder(binary.BigEndian)
+	var e *Error
+	err := bb.ReadUint16s(make([]uint16, 4))
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, "ReadUint16s", e.Op)
+	assert.Equal(t, 8, e.Requested)
+	assert.Equal(t, 7, e.Got)
+
+	bw := Writer{}
+	assert.True(t, errors.Is(bw.WriteUint16s([]uint16{1}), ErrNilSource))
+	assert.True(t, errors.Is(bw.WriteFloat32s([]float32{1}), ErrNilSource))
+	bw = NewWriter(blackHole, nil)
+	assert.True(t, errors.Is(bw.WriteUint32s([]uint32{1}), ErrNilByteOrder))
+	assert.True(t, errors.Is(bw.WriteUint64s(nil), ErrNilByteOrder))
+	for _, bo := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		bw = NewWriter(&limitedWriter{n: 1000}, bo)
+		err = bw.WriteUint64s(make([]uint64, 200))
+		assert.True(t, errors.As(err, &e))
+		assert.Equal(t, "WriteUint64s", e.Op)
+		assert.Equal(t, 1600, e.Requested)
+		assert.Equal(t, 1000, e.Got)
+		bw = NewWriter(errRW, bo)
+		assert.Error(t, bw.WriteUint16s([]uint16{1}))
+		assert.Error(t, bw.WriteUint32s([]uint32{1}))
+	}
+}
+
+func TestSlicesAllocs(t *testing.T) {
+	f32 := make([]float32, 1000)
+	for _, bo := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		bb := NewReader(blackHole, bo)
+		bw := NewWriter(blackHole, bo)
+		allocs := testing.AllocsPerRun(100, func() {
+			_ = bb.ReadFloat32s(f32)
+			_ = bw.WriteFloat32s(f32)
+		})
+		assert.Equal(t, 0.0, allocs)
+	}
+}
+
+/*
+===============================================================================
+    Hashing
+===============================================================================
+*/
+
+func TestHashCRC32(t *testing.T) {
+	t.Parallel()
+	for _, bo := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		out := bytes.NewBuffer([]byte{})
+		bw := NewWriter(out, bo)
+		assert.NoError(t, bw.WriteByte(0xFF)) // not hashed
+		bw.BeginHash(crc32.NewIEEE())
+		assert.NoError(t, bw.WriteUint32(0xDEADBEEF))
+		assert.NoError(t, bw.WriteCString("header"))
+		assert.NoError(t, bw.WriteCRC32())
+		assert.Nil(t, bw.EndHash())
+		assert.Equal(t, crc32.ChecksumIEEE(out.Bytes()[1:12]), bo.Uint32(out.Bytes()[12:]))
+
+		bb := NewReaderBytes(out.Bytes(), bo)
+		var u32 uint32
+		s := ""
+		assert.NoError(t, bb.Discard(1))
+		bb.BeginHash(crc32.NewIEEE())
+		assert.NoError(t, bb.ReadUint32(&u32))
+		assert.NoError(t, bb.ReadCString(&s, 16))
+		assert.NoError(t, bb.ExpectCRC32())
+		assert.Equal(t, int64(16), bb.GetPosition())
+
+		// any 32-bit hash may be used
+		bb = NewReaderBytes(out.Bytes(), bo)
+		bb.BeginHash(adler32.New())
+		assert.NoError(t, bb.Discard(12))
+		err := bb.ExpectCRC32()
+		assert.True(t, errors.Is(err, ErrChecksumMismatch))
+		var e *Error
+		assert.True(t, errors.As(err, &e))
+		assert.Equal(t, int64(12), e.Offset)
+
+		// corruption is detected
+		corrupt := append([]byte{}, out.Bytes()...)
+		corrupt[3] ^= 1
+		bb = NewReaderBytes(corrupt, bo)
+		assert.NoError(t, bb.Discard(1))
+		bb.BeginHash(crc32.NewIEEE())
+		assert.NoError(t, bb.Discard(11))
+		assert.True(t, errors.Is(bb.ExpectCRC32(), ErrChecksumMismatch))
+	}
+}
+
+func TestHashSum(t *testing.T) {
+	t.Parallel()
+	out := bytes.NewBuffer([]byte{})
+	bw := NewBufferedWriter(out, binary.BigEndian, 16)
+	bw.BeginHash(sha256.New())
+	assert.NoError(t, bw.WriteUint16(1))
+	bw.BeginHash(crc32.NewIEEE()) // nested
+	assert.NoError(t, bw.WriteBytes(testBuffer))
+	assert.NoError(t, bw.WriteCRC32())
+	assert.NoError(t, bw.ZeroFill(2))
+	assert.NoError(t, bw.WriteSum())
+	assert.NoError(t, bw.Flush())
+	data := out.Bytes()
+	sum := sha256.Sum256(data[:len(data)-32])
+	assert.Equal(t, sum[:], data[len(data)-32:])
+
+	bb := NewReaderBytes(data, binary.BigEndian)
+	bb.BeginHash(sha256.New())
+	assert.NoError(t, bb.Discard(2))
+	bb.BeginHash(crc32.NewIEEE())
+	_, err := bb.Next(len(testBuffer))
+	assert.NoError(t, err)
+	assert.NoError(t, bb.ExpectCRC32())
+	assert.NoError(t, bb.Discard(2))
+	assert.NoError(t, bb.ExpectSum())
+
+	data[0] ^= 1
+	bb = NewReaderBytes(data, binary.BigEndian)
+	bb.BeginHash(sha256.New())
+	assert.NoError(t, bb.Discard(int64(len(data)-32)))
+	assert.True(t, errors.Is(bb.ExpectSum(), ErrChecksumMismatch))
+}
+
+func TestHashConsumedOnly(t *testing.T) {
+	t.Parallel()
+	bb := NewReaderBytes(testBuffer, nil)
+	assert.NoError(t, bb.Peek(make([]byte, 8)))
+	h := crc32.NewIEEE()
+	bb.BeginHash(h)
+	buf := make([]byte, 2)
+	assert.NoError(t, bb.ReadBytes(buf))
+	n, err := bb.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.NoError(t, bb.Peek(make([]byte, 12)))
+	_, err = bb.Seek(2, io.SeekCurrent) // not hashed
+	assert.NoError(t, err)
+	assert.NoError(t, bb.Discard(1))
+	assert.NoError(t, bb.Peek(make([]byte, 4)))
+	assert.True(t, bb.EndHash() == h)
+	assert.Equal(t, crc32.ChecksumIEEE([]byte("12347")), h.Sum32())
+
+	// bytes read directly by a sub-reader are hashed by it and its parent
+	bb = NewReaderBytes(testBuffer, nil)
+	outer := crc32.NewIEEE()
+	bb.BeginHash(outer)
+	sub, err := bb.SubReader(4)
+	assert.NoError(t, err)
+	inner := crc32.NewIEEE()
+	sub.BeginHash(inner)
+	_, err = sub.Next(3)
+	assert.NoError(t, err)
+	assert.Equal(t, crc32.ChecksumIEEE([]byte("123")), inner.Sum32())
+	assert.Equal(t, crc32.ChecksumIEEE([]byte("123")), outer.Sum32())
+}
+
+func TestHashWriter(t *testing.T) {
+	t.Parallel()
+	h := crc32.NewIEEE()
+	bw := NewWriter(&bytes.Buffer{}, binary.LittleEndian)
+	bw.BeginHash(h)
+	_, err := bw.Write([]byte("ab"))
+	assert.NoError(t, err)
+	r, err := bw.Reserve(1) // held, and hashed as written
+	assert.NoError(t, err)
+	assert.NoError(t, bw.WriteBytes([]byte("cd")))
+	assert.NoError(t, r.PatchUint8('x'))
+	assert.True(t, bw.EndHash() == h)
+	assert.Equal(t, crc32.ChecksumIEEE([]byte("ab\x00cd")), h.Sum32())
+
+	// hashes are discarded by `Reset`
+	bw.BeginHash(h)
+	bw.Reset(&bytes.Buffer{}, nil)
+	assert.Nil(t, bw.EndHash())
+	bb := NewReaderBytes(nil, nil)
+	bb.BeginHash(h)
+	bb.Reset(bytes.NewReader(nil), nil)
+	assert.Nil(t, bb.EndHash())
+}
+
+func TestHashError(t *testing.T) {
+	t.Parallel()
+	bb := NewReaderBytes(make([]byte, 4), binary.LittleEndian)
+	assert.True(t, errors.Is(bb.ExpectCRC32(), errNoHash))
+	assert.True(t, errors.Is(bb.ExpectSum(), errNoHash))
+	h := sha256.New()
+	bb.BeginHash(h)
+	assert.True(t, errors.Is(bb.ExpectCRC32(), errNotHash32))
+	assert.True(t, bb.EndHash() == h) // not ended by the failure
+	bb.BeginHash(crc32.NewIEEE())
+	bb.SetByteOrder(nil)
+	assert.True(t, errors.Is(bb.ExpectCRC32(), ErrNilByteOrder))
+	bb.SetByteOrder(binary.LittleEndian)
+	assert.NoError(t, bb.Discard(2))
+	assert.True(t, errors.Is(bb.ExpectCRC32(), io.ErrUnexpectedEOF))
+	bb.BeginHash(sha256.New())
+	assert.True(t, errors.Is(bb.ExpectSum(), io.EOF))
+	bb = Reader{}
+	assert.True(t, errors.Is(bb.ExpectCRC32(), ErrNilSource))
+	assert.True(t, errors.Is(bb.ExpectSum(), ErrNilSource))
+
+	bw := NewWriter(blackHole, binary.LittleEndian)
+	assert.True(t, errors.Is(bw.WriteCRC32(), errNoHash))
+	assert.True(t, errors.Is(bw.WriteSum(), errNoHash))
+	bw.BeginHash(sha256.New())
+	assert.True(t, errors.Is(bw.WriteCRC32(), errNotHash32))
+	bw.SetByteOrder(nil)
+	assert.True(t, errors.Is(bw.WriteCRC32(), ErrNilByteOrder))
+	assert.NoError(t, bw.WriteSum())
+	bw = Writer{}
+	assert.True(t, errors.Is(bw.WriteCRC32(), ErrNilSource))
+	assert.True(t, errors.Is(bw.WriteSum(), ErrNilSource))
+}
+
+/*
+===============================================================================
+    Mark and Rewind
+===============================================================================
+*/
+
+// pipe hides all but the `io.Reader` methods of its source, as a pipe would.
+type pipe struct {
+	io.Reader
+}
+
+func TestMarkRewind(t *testing.T) {
+	t.Parallel()
+	src := bytes.Repeat(testBuffer, 100)
+	for _, bb := range []Reader{
+		NewReader(pipe{bytes.NewReader(src)}, binary.BigEndian),
+		NewReaderBytes(src, binary.BigEndian),
+	} {
+		var u32 uint32
+		assert.NoError(t, bb.Discard(2))
+		bb.Mark()
+		assert.NoError(t, bb.ReadUint32(&u32))
+		assert.Equal(t, uint32(0x33343536), u32)
+		assert.NoError(t, bb.Peek(make([]byte, 8)))
+		p, err := bb.Next(3)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("789"), p)
+		buf := make([]byte, 5)
+		n, err := bb.Read(buf)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("0abcd")[:n], buf[:n])
+		assert.NoError(t, bb.Discard(2000))
+		large := make([]byte, 1500)
+		assert.NoError(t, bb.ReadBytes(large))
+		end := bb.GetPosition()
+
+		// the same bytes are read again, any number of times
+		for i := 0; i < 2; i++ {
+			assert.NoError(t, bb.Rewind())
+			assert.Equal(t, int64(2), bb.GetPosition())
+			replay := make([]byte, end-2)
+			assert.NoError(t, bb.ReadBytes(replay))
+			assert.Equal(t, src[2:end], replay)
+		}
+
+		// `Release` removes the mark, but not the bytes read ahead
+		assert.NoError(t, bb.Rewind())
+		assert.NoError(t, bb.Discard(4))
+		bb.Release()
+		assert.True(t, errors.Is(bb.Rewind(), errNoMark))
+		assert.NoError(t, bb.ReadBytes(buf))
+		assert.Equal(t, src[6:11], buf)
+	}
+}
+
+func TestMarkLimit(t *testing.T) {
+	t.Parallel()
+	src := bytes.Repeat(testBuffer, 100)
+	bb := NewReader(pipe{bytes.NewReader(src)}, nil)
+	bb.SetRetentionLimit(16)
+	bb.Mark()
+	assert.NoError(t, bb.Discard(16))
+	assert.NoError(t, bb.Rewind())
+	assert.NoError(t, bb.Discard(17))
+	assert.True(t, errors.Is(bb.Rewind(), errMarkLost))
+	buf := make([]byte, 4)
+	assert.NoError(t, bb.ReadBytes(buf))
+	assert.Equal(t, src[17:21], buf)
+
+	// including bytes consumed after being peeked
+	bb.Mark()
+	assert.NoError(t, bb.Peek(make([]byte, 32)))
+	assert.NoError(t, bb.Discard(20))
+	assert.True(t, errors.Is(bb.Rewind(), errMarkLost))
+	assert.NoError(t, bb.ReadBytes(buf))
+	assert.Equal(t, src[41:45], buf)
+
+	// retention is independent of the read-ahead limit
+	bb = NewReader(pipe{bytes.NewReader(src)}, nil)
+	bb.SetReadAheadLimit(64)
+	bb.SetRetentionLimit(-1)
+	bb.Mark()
+	large := make([]byte, 3000)
+	assert.NoError(t, bb.ReadBytes(large))
+	assert.NoError(t, bb.Rewind())
+	assert.NoError(t, bb.ReadBytes(large))
+	assert.Equal(t, src[:3000], large)
+
+	// and the buffer is bounded again once released
+	bb.Release()
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, bb.Peek(buf))
+		assert.NoError(t, bb.Discard(3))
+	}
+	assert.True(t, len(bb.buf)-bb.off <= 64)
+}
+
+func TestMarkSeek(t *testing.T) {
+	t.Parallel()
+	bb := NewReaderBytes(testBuffer, nil)
+	bb.Mark()
+	assert.NoError(t, bb.Peek(make([]byte, 8)))
+	_, err := bb.Seek(4, io.SeekCurrent) // within the buffer
+	assert.NoError(t, err)
+	assert.NoError(t, bb.Rewind())
+	assert.Equal(t, int64(0), bb.GetPosition())
+	assert.NoError(t, bb.SetPosition(10))
+	assert.True(t, errors.Is(bb.Rewind(), errMarkLost))
+	var c byte
+	assert.NoError(t, bb.ReadByte(&c))
+	assert.Equal(t, byte('a'), c)
+
+	// a mark may be set again, and is removed by `Reset`
+	bb.Mark()
+	assert.NoError(t, bb.ReadByte(&c))
+	assert.NoError(t, bb.Rewind())
+	assert.Equal(t, int64(11), bb.GetPosition())
+	bb.Reset(bytes.NewReader(testBuffer), nil)
+	assert.True(t, errors.Is(bb.Rewind(), errNoMark))
+
+	// sub-readers may be marked independently of their parent
+	bb = NewReader(pipe{bytes.NewReader(testBuffer)}, nil)
+	sub, err := bb.SubReader(8)
+	assert.NoError(t, err)
+	sub.Mark()
+	assert.NoError(t, sub.Discard(8))
+	assert.True(t, errors.Is(sub.Discard(1), ErrExceededContainer))
+	assert.NoError(t, sub.Rewind())
+	assert.NoError(t, sub.ReadByte(&c))
+	assert.Equal(t, byte('1'), c)
+	assert.NoError(t, sub.SkipRemaining())
+	assert.True(t, errors.Is(sub.Rewind(), errMarkLost))
+	assert.Equal(t, int64(8), bb.GetPosition())
+}
+
+/*
+===============================================================================
+    Arbitrary-Width Integers
+===============================================================================
+*/
+
+func TestUint24(t *testing.T) {
+	t.Parallel()
+	for _, c := range []struct {
+		bo       binary.ByteOrder
+		expected []byte
+	}{
+		{binary.LittleEndian, []byte{0x56, 0x34, 0x12, 0xFF, 0xFF, 0xFF, 0x00, 0x00, 0x80}},
+		{binary.BigEndian, []byte{0x12, 0x34, 0x56, 0xFF, 0xFF, 0xFF, 0x80, 0x00, 0x00}},
+		{customOrder{binary.BigEndian}, []byte{0x12, 0x34, 0x56, 0xFF, 0xFF, 0xFF, 0x80, 0x00, 0x00}},
+	} {
+		out := bytes.NewBuffer([]byte{})
+		bw := NewWriter(out, c.bo)
+		assert.NoError(t, bw.WriteUint24(0x123456))
+		assert.NoError(t, bw.WriteInt24(-1))
+		assert.NoError(t, bw.WriteInt24(-1<<23))
+		assert.Equal(t, c.expected, out.Bytes())
+
+		bb := NewReaderBytes(out.Bytes(), c.bo)
+		var u32 uint32
+		var i32 int32
+		assert.NoError(t, bb.ReadUint24(&u32))
+		assert.Equal(t, uint32(0x123456), u32)
+		assert.NoError(t, bb.ReadInt24(&i32))
+		assert.Equal(t, int32(-1), i32)
+		assert.NoError(t, bb.ReadInt24(&i32))
+		assert.Equal(t, int32(-1<<23), i32)
+		assert.Equal(t, int64(9), bb.GetPosition())
+	}
+}
+
+func TestUintN(t *testing.T) {
+	t.Parallel()
+	for _, bo := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian, customOrder{binary.LittleEndian}} {
+		for n := 1; n <= 8; n++ {
+			max := uint64(math.MaxUint64) >> (64 - 8*n)
+			min := int64(-1) << (8*n - 1)
+			out := bytes.NewBuffer([]byte{})
+			bw := NewWriter(out, bo)
+			assert.NoError(t, bw.WriteUintN(max, n))
+			assert.NoError(t, bw.WriteUintN(0x0102030405060708&max, n))
+			assert.NoError(t, bw.WriteIntN(min, n))
+			assert.NoError(t, bw.WriteIntN(-min-1, n))
+			assert.NoError(t, bw.WriteIntN(-2, n))
+			assert.Equal(t, 5*n, out.Len())
+
+			bb := NewReaderBytes(out.Bytes(), bo)
+			var u64 uint64
+			var i64 int64
+			assert.NoError(t, bb.ReadUintN(&u64, n))
+			assert.Equal(t, max, u64)
+			assert.NoError(t, bb.ReadUintN(&u64, n))
+			assert.Equal(t, 0x0102030405060708&max, u64)
+			assert.NoError(t, bb.ReadIntN(&i64, n))
+			assert.Equal(t, min, i64)
+			assert.NoError(t, bb.ReadIntN(&i64, n))
+			assert.Equal(t, -min-1, i64)
+			assert.NoError(t, bb.ReadIntN(&i64, n))
+			assert.Equal(t, int64(-2), i64)
+		}
+	}
+
+	// 48-bit big-endian, as used by MAC addresses and MPEG-TS clock references
+	bb := NewReaderBytes([]byte{0x00, 0x1A, 0x2B, 0x3C, 0x4D, 0x5E}, binary.BigEndian)
+	var u64 uint64
+	assert.NoError(t, bb.ReadUintN(&u64, 6))
+	assert.Equal(t, uint64(0x001A2B3C4D5E), u64)
+}
+
+func TestUintNError(t *testing.T) {
+	t.Parallel()
+	bb := NewReaderBytes(make([]byte, 4), binary.LittleEndian)
+	var u64 uint64
+	var i64 int64
+	var u32 uint32
+	var i32 int32
+	for _, n := range []int{0, -1, 9} {
+		assert.True(t, errors.Is(bb.ReadUintN(&u64, n), errBadWidth))
+		assert.True(t, errors.Is(bb.ReadIntN(&i64, n), errBadWidth))
+	}
+	assert.NoError(t, bb.ReadUint24(&u32))
+	assert.True(t, errors.Is(bb.ReadInt24(&i32), io.ErrUnexpectedEOF))
+	assert.True(t, errors.Is(bb.ReadIntN(&i64, 1), io.EOF))
+	bb.SetByteOrder(nil)
+	assert.True(t, errors.Is(bb.ReadUint24(&u32), ErrNilByteOrder))
+	bb = Reader{}
+	assert.True(t, errors.Is(bb.ReadUintN(&u64, 2), ErrNilSource))
+
+	bw := NewWriter(blackHole, binary.BigEndian)
+	assert.True(t, errors.Is(bw.WriteUint24(1<<24), ErrOverflow))
+	assert.True(t, errors.Is(bw.WriteInt24(1<<23), ErrOverflow))
+	assert.True(t, errors.Is(bw.WriteInt24(-1<<23-1), ErrOverflow))
+	assert.True(t, errors.Is(bw.WriteUintN(256, 1), ErrOverflow))
+	assert.True(t, errors.Is(bw.WriteIntN(128, 1), ErrOverflow))
+	assert.True(t, errors.Is(bw.WriteIntN(-129, 1), ErrOverflow))
+	assert.True(t, errors.Is(bw.WriteUintN(1, 0), errBadWidth))
+	assert.True(t, errors.Is(bw.WriteIntN(1, 9), errBadWidth))
+	assert.Equal(t, int64(0), bw.GetPosition())
+	bw.SetByteOrder(nil)
+	assert.True(t, errors.Is(bw.WriteUint24(1), ErrNilByteOrder))
+	bw = NewWriter(errRW, binary.BigEndian)
+	assert.Error(t, bw.WriteUintN(1, 5))
+	bw = Writer{}
+	assert.True(t, errors.Is(bw.WriteInt24(1), ErrNilSource))
+}
+
+func TestUintNAllocs(t *testing.T) {
+	bb := NewReader(blackHole, customOrder{binary.BigEndian})
+	bw := NewWriter(blackHole, customOrder{binary.BigEndian})
+	var u32 uint32
+	var i64 int64
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = bb.ReadUint24(&u32)
+		_ = bb.ReadIntN(&i64, 6)
+		_ = bw.WriteUint24(u32)
+		_ = bw.WriteIntN(i64, 6)
+	})
+	assert.Equal(t, 0.0, allocs)
+}
+
+/*
+===============================================================================
+    Half-Precision
+===============================================================================
+*/
+
+func TestFloat16(t *testing.T) {
+	t.Parallel()
+	for _, c := range []struct {
+		f float32
+		h uint16
+	}{
+		{0, 0x0000},
+		{float32(math.Copysign(0, -1)), 0x8000},
+		{1, 0x3c00},
+		{-2, 0xc000},
+		{0.333251953125, 0x3555},
+		{65504, 0x7bff},                // largest normal
+		{0x1p-14, 0x0400},              // smallest normal
+		{0x1p-24, 0x0001},              // smallest subnormal
+		{0x3ffp-24, 0x03ff},            // largest subnormal
+		{float32(math.Inf(1)), 0x7c00}, // infinities
+		{float32(math.Inf(-1)), 0xfc00},
+	} {
+		out := bytes.NewBuffer([]byte{})
+		bw := NewWriter(out, binary.BigEndian)
+		assert.NoError(t, bw.WriteFloat16(c.f))
+		assert.Equal(t, []byte{byte(c.h >> 8), byte(c.h)}, out.Bytes())
+		bb := NewReaderBytes(out.Bytes(), binary.BigEndian)
+		var f32 float32
+		assert.NoError(t, bb.ReadFloat16(&f32))
+		assert.Equal(t, math.Float32bits(c.f), math.Float32bits(f32))
+	}
+
+	// rounding to nearest, ties to even
+	for _, c := range []struct {
+		f float32
+		h uint16
+	}{
+		{1 + 0x1p-11, 0x3c00}, // tie, rounds down to even
+		{1 + 0x3p-11, 0x3c02}, // tie, rounds up to even
+		{1 + 0x1p-11 + 0x1p-20, 0x3c01},
+		{65519, 0x7bff}, // below the halfway point to 65536
+		{65520, 0x7c00}, // overflows to infinity
+		{1e10, 0x7c00},
+		{-1e10, 0xfc00},
+		{0x1p-25, 0x0000}, // tie with zero, rounds to even
+		{0x1.8p-25, 0x0001},
+		{0x1p-26, 0x0000},
+		{-0x1p-30, 0x8000},   // underflow keeps the sign
+		{0x3ff8p-28, 0x0400}, // rounds up from subnormal to normal
+		{0x1.ffcp-15, 0x0400},
+	} {
+		assert.Equal(t, c.h, float32to16(c.f), "%v", c.f)
+	}
+
+	// NaNs remain NaNs, keeping the upper payload
+	assert.Equal(t, uint16(0x7e00), float32to16(float32(math.NaN())))
+	assert.Equal(t, uint16(0xfd55), float32to16(math.Float32frombits(0xffaaa000)))
+	assert.Equal(t, uint16(0x7e00), float32to16(math.Float32frombits(0x7f800001)))
+	assert.Equal(t, uint32(0xffaaa000), math.Float32bits(float16to32(0xfd55)))
+
+	// every half-precision value round trips exactly
+	for h := 0; h <= 0xffff; h++ {
+		assert.Equal(t, uint16(h), float32to16(float16to32(uint16(h))))
+	}
 }
 
-func TestGetByteOrder(t *testing.T) {
+func TestBFloat16(t *testing.T) {
 	t.Parallel()
-	bb := NewReaderBytes([]byte{}, binary.LittleEndian)
-	assert.Equal(t, binary.LittleEndian, bb.GetByteOrder())
+	out := bytes.NewBuffer([]byte{})
+	bw := NewWriter(out, binary.LittleEndian)
+	assert.NoError(t, bw.WriteBFloat16(1))
+	assert.NoError(t, bw.WriteBFloat16(-3.140625))
+	assert.Equal(t, []byte{0x80, 0x3f, 0x49, 0xc0}, out.Bytes())
+	bb := NewReaderBytes(out.Bytes(), binary.LittleEndian)
+	var f32 float32
+	assert.NoError(t, bb.ReadBFloat16(&f32))
+	assert.Equal(t, float32(1), f32)
+	assert.NoError(t, bb.ReadBFloat16(&f32))
+	assert.Equal(t, float32(-3.140625), f32)
+
+	for _, c := range []struct {
+		f float32
+		h uint16
+	}{
+		{1 + 0x1p-8, 0x3f80}, // tie, rounds down to even
+		{1 + 0x3p-8, 0x3f82}, // tie, rounds up to even
+		{1 + 0x1p-8 + 0x1p-20, 0x3f81},
+		{math.MaxFloat32, 0x7f80}, // overflows to infinity
+		{0x1p-133, 0x0001},        // subnormal
+		{float32(math.Inf(-1)), 0xff80},
+		{float32(math.NaN()), 0x7fc0},
+		{math.Float32frombits(0x7f800001), 0x7fc0},
+		{math.Float32frombits(0xffaa1234), 0xffaa},
+	} {
+		assert.Equal(t, c.h, float32toB16(c.f), "%v", c.f)
+	}
 
-	bb = NewReaderBytes([]byte{}, binary.BigEndian)
-	assert.Equal(t, binary.BigEndian, bb.GetByteOrder())
+	for h := 0; h <= 0xffff; h++ {
+		assert.Equal(t, uint16(h), float32toB16(bfloat16to32(uint16(h))))
+	}
 }
 
-func TestSetByteOrder(t *testing.T) {
+func TestFloat16s(t *testing.T) {
 	t.Parallel()
-	bb := NewReaderBytes([]byte{}, binary.LittleEndian)
-	bb.SetByteOrder(binary.BigEndian)
-	assert.Equal(t, binary.BigEndian, bb.GetByteOrder())
-	bb.SetByteOrder(binary.LittleEndian)
-	assert.Equal(t, binary.LittleEndian, bb.GetByteOrder())
+	src := make([]float32, 1000)
+	for i := range src {
+		src[i] = float16to32(uint16(i * 65))
+	}
+	for _, bo := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian, customOrder{binary.BigEndian}} {
+		out := bytes.NewBuffer([]byte{})
+		bw := NewWriter(out, bo)
+		assert.NoError(t, bw.WriteFloat16s(src))
+		assert.NoError(t, bw.WriteBFloat16s(src[:3]))
+		assert.Equal(t, 2006, out.Len())
+		for i := range src {
+			assert.Equal(t, uint16(i*65), bo.Uint16(out.Bytes()[i*2:]))
+		}
+
+		bb := NewReaderBytes(out.Bytes(), bo)
+		dst := make([]float32, len(src))
+		assert.NoError(t, bb.ReadFloat16s(dst))
+		for i := range src {
+			assert.Equal(t, math.Float32bits(src[i]), math.Float32bits(dst[i]))
+		}
+		assert.NoError(t, bb.ReadBFloat16s(dst[:3]))
+		assert.Equal(t, []float32{0, float32toB16Value(src[1]), float32toB16Value(src[2])}, dst[:3])
+		assert.NoError(t, bb.ReadFloat16s(nil))
+		assert.True(t, errors.Is(bb.ReadBFloat16s(dst[:1]), io.EOF))
+	}
+
+	bb := NewReaderBytes([]byte{1, 2, 3}, binary.LittleEndian)
+	assert.True(t, errors.Is(bb.ReadFloat16s(make([]float32, 2)), io.ErrUnexpectedEOF))
+	bb.SetByteOrder(nil)
+	assert.True(t, errors.Is(bb.ReadFloat16(new(float32)), ErrNilByteOrder))
+	bb = Reader{}
+	assert.True(t, errors.Is(bb.ReadBFloat16s(make([]float32, 2)), ErrNilSource))
+
+	bw := NewWriter(errRW, binary.LittleEndian)
+	err := bw.WriteFloat16s(src)
+	var e *Error
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, "WriteFloat16s", e.Op)
+	assert.Equal(t, 2000, e.Requested)
+	bw.SetByteOrder(nil)
+	assert.True(t, errors.Is(bw.WriteBFloat16(1), ErrNilByteOrder))
+	bw = Writer{}
+	assert.True(t, errors.Is(bw.WriteFloat16(1), ErrNilSource))
+}
+
+func TestFloat16sAllocs(t *testing.T) {
+	bb := NewReader(blackHole, binary.BigEndian)
+	bw := NewWriter(blackHole, binary.BigEndian)
+	buf := make([]float32, 256)
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = bb.ReadFloat16s(buf)
+		_ = bw.WriteBFloat16s(buf)
+		_ = bw.WriteFloat16(buf[0])
+	})
+	assert.Equal(t, 0.0, allocs)
+}
+
+// float32toB16Value rounds `f` to the nearest bfloat16 value.
+func float32toB16Value(f float32) float32 {
+	return bfloat16to32(float32toB16(f))
 }
 
 // Benchmarks
@@ -941,6 +4692,10 @@ func (errorRW) Read(p []byte) (int, error) {
 	return 0, errors.New("error")
 }
 
+func (errorRW) ReadAt(p []byte, off int64) (int, error) {
+	return 0, errors.New("error")
+}
+
 func (negativeRW) Write(p []byte) (int, error) {
 	return -len(p), nil
 }
@@ -948,6 +4703,98 @@ func (negativeRW) Write(p []byte) (int, error) {
 func (negativeRW) Read(p []byte) (int, error) {
 	return -len(p), nil
 }
+
+// loopReader endlessly repeats the contents of a buffer.
+type loopReader struct {
+	buf []byte
+	off int
+}
+
+func (l *loopReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		c := copy(p[n:], l.buf[l.off:])
+		n += c
+		l.off = (l.off + c) % len(l.buf)
+	}
+	return n, nil
+}
+
+// countingReader counts the calls made to `Read`.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	c.n++
+	return c.r.Read(p)
+}
+
+// limitedWriter accepts `n` bytes, then returns an error.
+type limitedWriter struct {
+	n int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > l.n {
+		n := l.n
+		l.n = 0
+		return n, errors.New("limit reached")
+	}
+	l.n -= len(p)
+	return len(p), nil
+}
+
+// countingWriter counts the calls made to `Write`.
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n++
+	return c.w.Write(p)
+}
+
+// seekBuffer is an in-memory `io.WriteSeeker`.
+type seekBuffer struct {
+	buf      []byte
+	off      int
+	failSeek bool
+}
+
+func (s *seekBuffer) Write(p []byte) (int, error) {
+	if n := s.off + len(p); n > len(s.buf) {
+		s.buf = append(s.buf, make([]byte, n-len(s.buf))...)
+	}
+	s.off += copy(s.buf[s.off:], p)
+	return len(p), nil
+}
+
+func (s *seekBuffer) Seek(offset int64, whence int) (int64, error) {
+	if s.failSeek {
+		return 0, errors.New("seek failed")
+	}
+	switch whence {
+	case io.SeekCurrent:
+		offset += int64(s.off)
+	case io.SeekEnd:
+		offset += int64(len(s.buf))
+	}
+	s.off = int(offset)
+	return offset, nil
+}
+
+// unseekable is an `io.WriteSeeker` whose `Seek` always fails, such as a pipe.
+type unseekable struct {
+	io.Writer
+}
+
+func (unseekable) Seek(int64, int) (int64, error) {
+	return 0, errors.New("illegal seek")
+}
+
 func BenchmarkReadByte(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		err = brLE.ReadByte(&c)
@@ -998,7 +4845,7 @@ func BenchmarkPeek(b *testing.B) {
 				}
 				brLE.Reset(blackHole, binary.LittleEndian)
 				// to prevent re-use across benchmark runs:
-				//brLE.peekBuffer = make([]byte, 64)
+				//brLE.buf = nil
 				// (but this could be argued to be unrealistic, as the reader will
 				// not likely be reset every call to Peek)
 			}
@@ -1020,6 +4867,25 @@ func BenchmarkPeekThenRead(b *testing.B) {
 	}
 }
 
+func BenchmarkNext(b *testing.B) {
+	br := NewReaderBytes(make([]byte, 1<<20), binary.LittleEndian)
+	b.Run("ReadBytes", func(b *testing.B) {
+		buf := make([]byte, 256)
+		for i := 0; i < b.N; i++ {
+			if err := br.ReadBytes(buf); err != nil {
+				br.SetPosition(0)
+			}
+		}
+	})
+	b.Run("Next", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := br.Next(256); err != nil {
+				br.SetPosition(0)
+			}
+		}
+	})
+}
+
 func BenchmarkWriteBytes(b *testing.B) {
 	benchmarks := [][]byte{
 		make([]byte, 4),
@@ -1053,6 +4919,45 @@ func BenchmarkReadUint16(b *testing.B) {
 	}
 }
 
+func BenchmarkReadInt16(b *testing.B) {
+	i16 := int16(9000)
+	for i := 0; i < b.N; i++ {
+		err = brLE.ReadInt16(&i16)
+		if err != nil {
+			panic(err)
+		}
+		if i16 != 0 {
+			panic("i16 != 0")
+		}
+	}
+}
+
+func BenchmarkReadInt32(b *testing.B) {
+	i32 := int32(9000)
+	for i := 0; i < b.N; i++ {
+		err = brLE.ReadInt32(&i32)
+		if err != nil {
+			panic(err)
+		}
+		if i32 != 0 {
+			panic("i32 != 0")
+		}
+	}
+}
+
+func BenchmarkReadInt64(b *testing.B) {
+	i64 := int64(9000)
+	for i := 0; i < b.N; i++ {
+		err = brLE.ReadInt64(&i64)
+		if err != nil {
+			panic(err)
+		}
+		if i64 != 0 {
+			panic("i64 != 0")
+		}
+	}
+}
+
 func BenchmarkWriteUint16(b *testing.B) {
 	ui16 := uint16(9000)
 	for i := 0; i < b.N; i++ {
@@ -1063,6 +4968,56 @@ func BenchmarkWriteUint16(b *testing.B) {
 	}
 }
 
+// BenchmarkWriteUint16File compares `BenchmarkWriteUint16` against a file,
+// for which each unbuffered write is a system call.
+func BenchmarkWriteUint16File(b *testing.B) {
+	f, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		b.Skip(err)
+	}
+	defer f.Close()
+	for _, bench := range []struct {
+		name string
+		bw   Writer
+	}{
+		{"Unbuffered", NewWriter(f, binary.LittleEndian)},
+		{"Buffered", NewBufferedWriter(f, binary.LittleEndian, 0)},
+	} {
+		bw := bench.bw
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := bw.WriteUint16(9000); err != nil {
+					b.Fatal(err)
+				}
+			}
+			if err := bw.Flush(); err != nil {
+				b.Fatal(err)
+			}
+		})
+	}
+}
+
+func BenchmarkWriteUint16Buffered(b *testing.B) {
+	bw := NewBufferedWriter(blackHole, binary.LittleEndian, 0)
+	for i := 0; i < b.N; i++ {
+		err = bw.WriteUint16(9000)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+func BenchmarkWriteInt16(b *testing.B) {
+	i16 := int16(-9000)
+	for i := 0; i < b.N; i++ {
+		err = bwLE.WriteInt16(i16)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
 func BenchmarkReadUint32(b *testing.B) {
 	ui32 := uint32(9000)
 	for i := 0; i < b.N; i++ {
@@ -1076,6 +5031,17 @@ func BenchmarkReadUint32(b *testing.B) {
 	}
 }
 
+func BenchmarkUint32(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if brLE.Uint32() != 0 {
+			panic("Uint32() != 0")
+		}
+	}
+	if brLE.Err() != nil {
+		panic(brLE.Err())
+	}
+}
+
 func BenchmarkReadUint64(b *testing.B) {
 	ui64 := uint64(9000)
 	for i := 0; i < b.N; i++ {
@@ -1089,6 +5055,188 @@ func BenchmarkReadUint64(b *testing.B) {
 	}
 }
 
+func BenchmarkReadFloat32s(b *testing.B) {
+	f32 := make([]float32, 1024)
+	b.Run("ReadFloat32", func(b *testing.B) {
+		b.SetBytes(4096)
+		for i := 0; i < b.N; i++ {
+			for j := range f32 {
+				if err := brLE.ReadFloat32(&f32[j]); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	for _, br := range []Reader{brLE, brBE} {
+		br := br
+		b.Run(fmt.Sprint(br.GetByteOrder()), func(b *testing.B) {
+			b.SetBytes(4096)
+			for i := 0; i < b.N; i++ {
+				if err := br.ReadFloat32s(f32); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkWriteFloat32s(b *testing.B) {
+	f32 := make([]float32, 1024)
+	b.Run("WriteFloat32", func(b *testing.B) {
+		b.SetBytes(4096)
+		for i := 0; i < b.N; i++ {
+			for j := range f32 {
+				if err := bwLE.WriteFloat32(f32[j]); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	for _, bo := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		bw := NewWriter(blackHole, bo)
+		b.Run(fmt.Sprint(bo), func(b *testing.B) {
+			b.SetBytes(4096)
+			for i := 0; i < b.N; i++ {
+				if err := bw.WriteFloat32s(f32); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkReadUint24(b *testing.B) {
+	var u32 uint32
+	for i := 0; i < b.N; i++ {
+		err = brLE.ReadUint24(&u32)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+func BenchmarkWriteUint24(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		err = bwLE.WriteUint24(0x123456)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+func BenchmarkReadFloat16s(b *testing.B) {
+	f32 := make([]float32, 1024)
+	b.SetBytes(2048)
+	for i := 0; i < b.N; i++ {
+		if err := brLE.ReadFloat16s(f32); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWriteFloat16s(b *testing.B) {
+	f32 := make([]float32, 1024)
+	b.SetBytes(2048)
+	for i := 0; i < b.N; i++ {
+		if err := bwLE.WriteFloat16s(f32); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadUvarint(b *testing.B) {
+	ux := uint64(9000)
+	for i := 0; i < b.N; i++ {
+		err = brLE.ReadUvarint(&ux)
+		if err != nil {
+			panic(err)
+		}
+		if ux != 0 {
+			panic("ux != 0")
+		}
+	}
+}
+
+func BenchmarkWriteUvarint(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		err = bwLE.WriteUvarint(uint64(i))
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+func BenchmarkReadBits(b *testing.B) {
+	r := NewReader(blackHole, binary.LittleEndian)
+	br := NewBitReader(&r, MSBFirst)
+	v := uint64(9000)
+	for i := 0; i < b.N; i++ {
+		err = br.ReadBits(&v, 13)
+		if err != nil {
+			panic(err)
+		}
+		if v != 0 {
+			panic("v != 0")
+		}
+	}
+}
+
+func BenchmarkWriteBits(b *testing.B) {
+	w := NewWriter(blackHole, binary.LittleEndian)
+	bw := NewBitWriter(&w, MSBFirst)
+	for i := 0; i < b.N; i++ {
+		err = bw.WriteBits(uint64(i), 13)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+func BenchmarkReadUint32At(b *testing.B) {
+	ra := NewReaderAtBytes(make([]byte, 4096), binary.LittleEndian)
+	ui32 := uint32(9000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		err = ra.ReadUint32At(&ui32, int64(i%1024)*4)
+		if err != nil {
+			panic(err)
+		}
+		if ui32 != 0 {
+			panic("ui32 != 0")
+		}
+	}
+}
+
+func BenchmarkReadStruct(b *testing.B) {
+	v := struct {
+		A uint16
+		B uint32
+		C [4]byte
+		D float64
+	}{}
+	for i := 0; i < b.N; i++ {
+		err = brLE.ReadStruct(&v)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+func BenchmarkWriteStruct(b *testing.B) {
+	v := struct {
+		A uint16
+		B uint32
+		C [4]byte
+		D float64
+	}{}
+	for i := 0; i < b.N; i++ {
+		err = bwLE.WriteStruct(&v)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
 func BenchmarkDiscard(b *testing.B) {
 	benchmarks := []int64{
 		32,
@@ -0,0 +1,340 @@
+package bin
+
+import (
+	"fmt"
+)
+
+/*
+===============================================================================
+    Data Types
+===============================================================================
+*/
+
+// BitOrder specifies the order in which bit fields are packed into each byte.
+type BitOrder int
+
+const (
+	// MSBFirst packs fields starting at the most significant bit of each byte,
+	// as used by (for example) MPEG, H.264 and JPEG.
+	MSBFirst BitOrder = iota
+	// LSBFirst packs fields starting at the least significant bit of each byte,
+	// as used by (for example) DEFLATE and GIF.
+	LSBFirst
+)
+
+// maxPeekBits is the largest field that can be held in the bit cache at once.
+const maxPeekBits = 56
+
+// BitReader provides methods for reading bit-packed fields from a `Reader`.
+//
+// Bytes are taken from the underlying `Reader` only as they are needed, so
+// after a call to `AlignToByte` the `Reader` is positioned at the next unread
+// byte (unless `PeekBits` has buffered bits beyond it).
+type BitReader struct {
+	r     *Reader
+	order BitOrder
+	cache uint64 // bits taken from `r` but not yet consumed
+	nBits uint   // number of valid bits in `cache`
+	c     byte
+}
+
+// BitWriter provides methods for writing bit-packed fields to a `Writer`.
+//
+// Completed bytes are passed to the underlying `Writer` as soon as they are
+// filled, so at most seven bits are ever pending. `Flush` (or `AlignToByte`)
+// must be called to pad and write out a trailing partial byte.
+type BitWriter struct {
+	w       *Writer
+	order   BitOrder
+	cache   uint64 // bits not yet written to `w`
+	nBits   uint   // number of valid bits in `cache`
+	padding bool   // the value used to pad partial bytes
+	buf     [8]byte
+}
+
+/*
+===============================================================================
+    BitReader
+===============================================================================
+*/
+
+// ReadBits reads an `n`-bit unsigned field into `dst`, according to the
+// current bit order. `n` may be at most 64.
+func (b *BitReader) ReadBits(dst *uint64, n uint) error {
+	if b.r == nil {
+		return &Error{Op: "ReadBits", Err: ErrNilSource}
+	}
+	if n > 64 {
+		return b.r.newError("ReadBits", errTooManyBits)
+	}
+	if n <= maxPeekBits {
+		if err := b.fill("ReadBits", n); err != nil {
+			return err
+		}
+		*dst = b.take(n)
+		return nil
+	}
+	// too large for the cache, so split the field in two
+	if err := b.fill("ReadBits", n-32); err != nil {
+		return err
+	}
+	first := b.take(n - 32)
+	if err := b.fill("ReadBits", 32); err != nil {
+		return err
+	}
+	second := b.take(32)
+	if b.order == LSBFirst {
+		*dst = first | second<<(n-32)
+	} else {
+		*dst = first<<32 | second
+	}
+	return nil
+}
+
+// ReadBool reads a single bit into `dst`.
+func (b *BitReader) ReadBool(dst *bool) error {
+	if b.r == nil {
+		return &Error{Op: "ReadBool", Err: ErrNilSource}
+	}
+	if err := b.fill("ReadBool", 1); err != nil {
+		return err
+	}
+	*dst = b.take(1) == 1
+	return nil
+}
+
+// PeekBits reads an `n`-bit unsigned field into `dst` without advancing the
+// bit position. `n` may be at most 56.
+func (b *BitReader) PeekBits(dst *uint64, n uint) error {
+	if b.r == nil {
+		return &Error{Op: "PeekBits", Err: ErrNilSource}
+	}
+	if n > maxPeekBits {
+		return b.r.newError("PeekBits", fmt.Errorf("%w: cannot peek more than %d bits", ErrTooLong, maxPeekBits))
+	}
+	if err := b.fill("PeekBits", n); err != nil {
+		return err
+	}
+	if b.order == LSBFirst {
+		*dst = b.cache & bitMask(n)
+	} else {
+		*dst = (b.cache >> (b.nBits - n)) & bitMask(n)
+	}
+	return nil
+}
+
+// AlignToByte discards any remaining bits of a partially-consumed byte, such
+// that the next read begins on a byte boundary.
+func (b *BitReader) AlignToByte() {
+	b.take(b.nBits % 8)
+}
+
+// GetBitPosition returns the current offset, in bits, from the start of the
+// underlying `Reader`.
+func (b *BitReader) GetBitPosition() int64 {
+	if b.r == nil {
+		return 0
+	}
+	return b.r.GetPosition()*8 - int64(b.nBits)
+}
+
+// SetBitOrder sets the current bit order to `order`.
+// This discards any buffered bits, so should be done on a byte boundary.
+func (b *BitReader) SetBitOrder(order BitOrder) {
+	b.order = order
+	b.cache = 0
+	b.nBits = 0
+}
+
+// GetBitOrder returns the current bit order.
+func (b *BitReader) GetBitOrder() BitOrder {
+	return b.order
+}
+
+// fill takes whole bytes from the underlying reader until at least `n` bits
+// are cached, describing any failure as an `*Error` for the operation `op`.
+// `n` must not exceed `maxPeekBits`.
+func (b *BitReader) fill(op string, n uint) error {
+	for b.nBits < n {
+		if b.r.source == nil {
+			return b.r.newError(op, ErrNilSource)
+		}
+		if err := b.r.readFull(op, b.r._1kb[:1]); err != nil {
+			return err
+		}
+		b.c = b.r._1kb[0]
+		if b.order == LSBFirst {
+			b.cache |= uint64(b.c) << b.nBits
+		} else {
+			b.cache = b.cache<<8 | uint64(b.c)
+		}
+		b.nBits += 8
+	}
+	return nil
+}
+
+// take consumes `n` cached bits. The caller must ensure they are available.
+func (b *BitReader) take(n uint) (v uint64) {
+	if b.order == LSBFirst {
+		v = b.cache & bitMask(n)
+		b.cache >>= n
+		b.nBits -= n
+		return
+	}
+	b.nBits -= n
+	v = (b.cache >> b.nBits) & bitMask(n)
+	b.cache &= bitMask(b.nBits)
+	return
+}
+
+// bitMask returns a mask covering the lowest `n` bits.
+func bitMask(n uint) uint64 {
+	if n >= 64 {
+		return ^uint64(0)
+	}
+	return (1 << n) - 1
+}
+
+// NewBitReader creates a new `BitReader` reading bit fields from `r`,
+// using `order` to specify how fields are packed into each byte.
+//
+// For futureproofing, it is suggested to use this constructor rather than
+// manually creating an instance (i.e. `br := BitReader{}`)
+func NewBitReader(r *Reader, order BitOrder) BitReader {
+	return BitReader{r: r, order: order}
+}
+
+/*
+===============================================================================
+    BitWriter
+===============================================================================
+*/
+
+// WriteBits writes the lowest `n` bits of `src` as an unsigned field,
+// according to the current bit order. `n` may be at most 64.
+func (b *BitWriter) WriteBits(src uint64, n uint) error {
+	if b.w == nil {
+		return &Error{Op: "WriteBits", Err: ErrNilSource}
+	}
+	if n > 64 {
+		return b.w.newError("WriteBits", errTooManyBits)
+	}
+	src &= bitMask(n)
+	if n <= maxPeekBits {
+		return b.put("WriteBits", src, n)
+	}
+	// too large for the cache, so split the field in two
+	if b.order == LSBFirst {
+		if err := b.put("WriteBits", src&bitMask(n-32), n-32); err != nil {
+			return err
+		}
+		return b.put("WriteBits", src>>(n-32), 32)
+	}
+	if err := b.put("WriteBits", src>>32, n-32); err != nil {
+		return err
+	}
+	return b.put("WriteBits", src&bitMask(32), 32)
+}
+
+// WriteBool writes `src` as a single bit.
+func (b *BitWriter) WriteBool(src bool) error {
+	if b.w == nil {
+		return &Error{Op: "WriteBool", Err: ErrNilSource}
+	}
+	if src {
+		return b.put("WriteBool", 1, 1)
+	}
+	return b.put("WriteBool", 0, 1)
+}
+
+// AlignToByte pads any partially-written byte with the padding bit (see
+// `SetPaddingBit`) and writes it, such that the next field begins on a byte
+// boundary.
+func (b *BitWriter) AlignToByte() error {
+	if b.w == nil {
+		return &Error{Op: "AlignToByte", Err: ErrNilSource}
+	}
+	if b.nBits == 0 {
+		return nil
+	}
+	n := 8 - b.nBits
+	if b.padding {
+		return b.put("AlignToByte", bitMask(n), n)
+	}
+	return b.put("AlignToByte", 0, n)
+}
+
+// Flush pads and writes out any partially-written byte.
+// It should be called once all fields have been written, and before writing
+// to the underlying `Writer` directly.
+func (b *BitWriter) Flush() error {
+	return b.AlignToByte()
+}
+
+// GetBitPosition returns the current offset, in bits, from the start of the
+// underlying `Writer`.
+func (b *BitWriter) GetBitPosition() int64 {
+	if b.w == nil {
+		return 0
+	}
+	return b.w.GetPosition()*8 + int64(b.nBits)
+}
+
+// SetPaddingBit sets the value of the bits used to pad partial bytes in
+// `AlignToByte` and `Flush`. The default is to pad with zeros.
+func (b *BitWriter) SetPaddingBit(set bool) {
+	b.padding = set
+}
+
+// SetBitOrder sets the current bit order to `order`.
+// This should only be done on a byte boundary.
+func (b *BitWriter) SetBitOrder(order BitOrder) {
+	b.order = order
+}
+
+// GetBitOrder returns the current bit order.
+func (b *BitWriter) GetBitOrder() BitOrder {
+	return b.order
+}
+
+// put appends the `n` (at most `maxPeekBits`) bits of `v` to the cache, then
+// writes out any completed bytes, describing any failure as an `*Error` for
+// the operation `op`. The cache is only updated once they have been written,
+// so a failed write leaves the `BitWriter` as it was.
+func (b *BitWriter) put(op string, v uint64, n uint) error {
+	if b.w.dest == nil {
+		return b.w.newError(op, ErrNilSource)
+	}
+	cache, nBits := b.cache, b.nBits
+	if b.order == LSBFirst {
+		cache |= v << nBits
+	} else {
+		cache = cache<<n | v
+	}
+	nBits += n
+	i := 0
+	for nBits >= 8 {
+		nBits -= 8
+		if b.order == LSBFirst {
+			b.buf[i] = byte(cache)
+			cache >>= 8
+		} else {
+			b.buf[i] = byte(cache >> nBits)
+		}
+		i++
+	}
+	if err := b.w.writeFull(op, b.buf[:i]); err != nil {
+		return err
+	}
+	b.cache, b.nBits = cache&bitMask(nBits), nBits
+	return nil
+}
+
+// NewBitWriter creates a new `BitWriter` writing bit fields to `w`,
+// using `order` to specify how fields are packed into each byte.
+//
+// For futureproofing, it is suggested to use this constructor rather than
+// manually creating an instance (i.e. `bw := BitWriter{}`)
+func NewBitWriter(w *Writer, order BitOrder) BitWriter {
+	return BitWriter{w: w, order: order}
+}
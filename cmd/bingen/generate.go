@@ -0,0 +1,658 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/b71729/bin/internal/structtag"
+)
+
+/*
+===============================================================================
+    Data Types
+===============================================================================
+*/
+
+// binPackage is the import path of the `bin` package.
+const binPackage = "github.com/b71729/bin"
+
+// basicType describes how a predeclared type is read and written.
+type basicType struct {
+	name   string // the predeclared type name
+	method string // the suffix of the `ReadXYZ`/`WriteXYZ` methods
+	size   int
+	signed bool
+}
+
+var basicTypes = map[string]basicType{
+	"bool":    {"bool", "", 1, false},
+	"byte":    {"byte", "Byte", 1, false},
+	"uint8":   {"uint8", "Byte", 1, false},
+	"int8":    {"int8", "Int8", 1, true},
+	"uint16":  {"uint16", "Uint16", 2, false},
+	"int16":   {"int16", "Int16", 2, true},
+	"uint32":  {"uint32", "Uint32", 4, false},
+	"int32":   {"int32", "Int32", 4, true},
+	"uint64":  {"uint64", "Uint64", 8, false},
+	"int64":   {"int64", "Int64", 8, true},
+	"float32": {"float32", "Float32", 4, false},
+	"float64": {"float64", "Float64", 8, false},
+}
+
+// tag holds the parsed options of a `bin:"..."` struct tag.
+type tag struct {
+	ignore   bool
+	bo       string // "binary.BigEndian", "binary.LittleEndian" or ""
+	skip     int64
+	lenField string
+}
+
+// field is a single (named) struct field.
+type field struct {
+	name string
+	typ  ast.Expr
+	tag  tag
+}
+
+// generator accumulates the generated source for a package.
+type generator struct {
+	buf      bytes.Buffer
+	pkg      string
+	specs    map[string]ast.Expr // all type declarations in the package
+	generate map[string]bool     // the types methods are generated for
+	imports  map[string]bool
+}
+
+/*
+===============================================================================
+    Parsing
+===============================================================================
+*/
+
+// generateDir parses the (non-test) Go files in `dir` and generates methods
+// for `typeNames`.
+func generateDir(dir string, typeNames []string) ([]byte, error) {
+	fset := token.NewFileSet()
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	files := []*ast.File{}
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		src, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		f, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Go files in %s", dir)
+	}
+	return generate(files, typeNames)
+}
+
+// generate generates methods for `typeNames`, which are declared in `files`.
+func generate(files []*ast.File, typeNames []string) ([]byte, error) {
+	g := generator{
+		pkg:      files[0].Name.Name,
+		specs:    map[string]ast.Expr{},
+		generate: map[string]bool{},
+		imports:  map[string]bool{binPackage: true},
+	}
+	for _, f := range files {
+		if f.Name.Name != g.pkg {
+			return nil, fmt.Errorf("multiple packages: %s and %s", g.pkg, f.Name.Name)
+		}
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				g.specs[ts.Name.Name] = ts.Type
+			}
+		}
+	}
+	for _, name := range typeNames {
+		if _, ok := g.specs[name].(*ast.StructType); !ok {
+			return nil, fmt.Errorf("struct type %s not found", name)
+		}
+		g.generate[name] = true
+	}
+	for _, name := range typeNames {
+		fields, err := g.fields(name)
+		if err != nil {
+			return nil, err
+		}
+		if err = g.genReadFrom(name, fields); err != nil {
+			return nil, err
+		}
+		if err = g.genWriteTo(name, fields); err != nil {
+			return nil, err
+		}
+	}
+
+	body := g.buf.Bytes()
+	g.buf = bytes.Buffer{}
+	g.printf("// Code generated by \"bingen -type=%s\"; DO NOT EDIT.\n\n", strings.Join(typeNames, ","))
+	g.printf("package %s\n\nimport (\n", g.pkg)
+	imports := []string{}
+	for path := range g.imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	for _, path := range imports {
+		if strings.Contains(path, ".") {
+			// separate the standard library from third-party packages
+			g.printf("\n")
+		}
+		g.printf("\t%q\n", path)
+	}
+	g.printf(")\n")
+	g.buf.Write(body)
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("internal error: invalid generated source: %v", err)
+	}
+	return src, nil
+}
+
+// fields returns the fields of the struct type `name`.
+func (g *generator) fields(name string) ([]field, error) {
+	fields := []field{}
+	for _, f := range g.specs[name].(*ast.StructType).Fields.List {
+		if len(f.Names) == 0 {
+			return nil, fmt.Errorf("%s: embedded fields are not supported", name)
+		}
+		t := tag{}
+		if f.Tag != nil {
+			lit, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+			st, err := structtag.Parse(reflect.StructTag(lit).Get("bin"))
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %v", name, f.Names[0].Name, err)
+			}
+			t = tag{ignore: st.Ignore, skip: st.Skip, lenField: st.LenField}
+			switch st.Order {
+			case binary.BigEndian:
+				t.bo = "binary.BigEndian"
+			case binary.LittleEndian:
+				t.bo = "binary.LittleEndian"
+			}
+		}
+		for _, n := range f.Names {
+			fields = append(fields, field{name: n.Name, typ: f.Type, tag: t})
+		}
+	}
+	return fields, nil
+}
+
+// basic returns the predeclared type underlying `typ`, if any, and whether
+// `typ` is a named type declared in the package.
+func (g *generator) basic(typ ast.Expr) (bt basicType, named bool, ok bool) {
+	ident, isIdent := typ.(*ast.Ident)
+	if !isIdent {
+		return
+	}
+	if bt, ok = basicTypes[ident.Name]; ok {
+		return
+	}
+	if under, declared := g.specs[ident.Name]; declared {
+		bt, _, ok = g.basic(under)
+		return bt, true, ok
+	}
+	return
+}
+
+// underlying resolves `typ` through any named types declared in the package,
+// stopping at structs for which methods are generated.
+func (g *generator) underlying(typ ast.Expr) ast.Expr {
+	for {
+		ident, ok := typ.(*ast.Ident)
+		if !ok || g.generate[ident.Name] {
+			return typ
+		}
+		under, declared := g.specs[ident.Name]
+		if !declared {
+			return typ
+		}
+		typ = under
+	}
+}
+
+// size returns the encoded size of the fixed-size type `typ`, following the
+// semantics of `binary.Size`.
+func (g *generator) size(typ ast.Expr) (int64, error) {
+	if bt, _, ok := g.basic(typ); ok {
+		return int64(bt.size), nil
+	}
+	switch t := g.underlying(typ).(type) {
+	case *ast.Ident:
+		if st, ok := g.specs[t.Name].(*ast.StructType); ok {
+			return g.size(st)
+		}
+	case *ast.ArrayType:
+		lit, ok := t.Len.(*ast.BasicLit)
+		if !ok || lit.Kind != token.INT {
+			break
+		}
+		n, err := strconv.ParseInt(lit.Value, 0, 64)
+		if err != nil {
+			return 0, err
+		}
+		elem, err := g.size(t.Elt)
+		return n * elem, err
+	case *ast.StructType:
+		total := int64(0)
+		for _, f := range t.Fields.List {
+			size, err := g.size(f.Type)
+			if err != nil {
+				return 0, err
+			}
+			total += size * int64(len(f.Names))
+		}
+		return total, nil
+	}
+	return 0, fmt.Errorf("%s is not fixed-size", types.ExprString(typ))
+}
+
+// lenField returns the field named `name`, which must precede `fields[i]`
+// and be an integer.
+func (g *generator) lenField(typeName string, fields []field, i int, name string) (field, basicType, error) {
+	for j := 0; j < i; j++ {
+		if fields[j].name != name {
+			continue
+		}
+		bt, _, ok := g.basic(fields[j].typ)
+		if !ok || bt.name == "bool" || strings.HasPrefix(bt.name, "float") {
+			return field{}, bt, fmt.Errorf("%s.%s: length field %s is not a fixed-size integer", typeName, fields[i].name, name)
+		}
+		return fields[j], bt, nil
+	}
+	return field{}, basicType{}, fmt.Errorf("%s.%s: length field %s does not precede it", typeName, fields[i].name, name)
+}
+
+/*
+===============================================================================
+    Generation
+===============================================================================
+*/
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// genReadFrom generates the `ReadFrom` method of `typeName`.
+func (g *generator) genReadFrom(typeName string, fields []field) error {
+	g.printf("\n// ReadFrom decodes `v` from `r`, according to the current byte order of `r`.\n")
+	g.printf("func (v *%s) ReadFrom(r *bin.Reader) error {\n", typeName)
+	g.declareByteOrder(fields, "r")
+	for i, f := range fields {
+		if f.tag.ignore {
+			continue
+		}
+		if f.tag.skip > 0 {
+			g.printf("if err := r.Discard(%d); err != nil {\nreturn err\n}\n", f.tag.skip)
+		}
+		if f.name == "_" {
+			size, err := g.size(f.typ)
+			if err != nil {
+				return fmt.Errorf("%s: blank field: %v", typeName, err)
+			}
+			g.printf("if err := r.Discard(%d); err != nil {\nreturn err\n}\n", size)
+			continue
+		}
+		if !ast.IsExported(f.name) {
+			continue
+		}
+		fail := "return "
+		if f.tag.bo != "" {
+			g.printf("r.SetByteOrder(%s)\n", f.tag.bo)
+			fail = "r.SetByteOrder(bo)\nreturn "
+		}
+		var err error
+		if f.tag.lenField != "" {
+			err = g.readSized(typeName, fields, i, fail)
+		} else {
+			err = g.read("v."+f.name, f.typ, 0, fail)
+		}
+		if err != nil {
+			return fmt.Errorf("%s.%s: %v", typeName, f.name, err)
+		}
+		if f.tag.bo != "" {
+			g.printf("r.SetByteOrder(bo)\n")
+		}
+	}
+	g.printf("return nil\n}\n")
+	return nil
+}
+
+// readSized generates the decoding of the slice or string `fields[i]`.
+func (g *generator) readSized(typeName string, fields []field, i int, fail string) error {
+	f := fields[i]
+	lf, bt, err := g.lenField(typeName, fields, i, f.tag.lenField)
+	if err != nil {
+		return err
+	}
+	target := "v." + f.name
+	if bt.signed {
+		g.imports["fmt"] = true
+		g.printf("if v.%s < 0 {\n%sfmt.Errorf(\"%s.%s: length %%d: %%w\", v.%s, bin.ErrNegativeLength)\n}\n", lf.name, fail, typeName, f.name, lf.name)
+	}
+	// lengths of 32 bits or more may not fit in an `int`, and are read from
+	// the input, so may be hostile: rather than being allocated up front,
+	// they are allocated as the values arrive
+	hostile := bt.size >= 4
+	if hostile {
+		g.imports["fmt"] = true
+		g.printf("if n := int(v.%s); n < 0 || uint64(n) != uint64(v.%s) {\n%sfmt.Errorf(\"%s.%s: length %%d: %%w\", v.%s, bin.ErrTooLong)\n}\n",
+			lf.name, lf.name, fail, typeName, f.name, lf.name)
+	}
+	if ident, ok := f.typ.(*ast.Ident); ok && ident.Name == "string" {
+		if hostile {
+			g.printf("{\nn := int(v.%s)\nvar buf []byte\n", lf.name)
+			g.readBytesGrowing("buf", fail)
+		} else {
+			g.printf("{\nbuf := make([]byte, v.%s)\n", lf.name)
+			g.printf("if err := r.ReadBytes(buf); err != nil {\n%serr\n}\n", fail)
+		}
+		g.printf("%s = string(buf)\n}\n", target)
+		return nil
+	}
+	slice, ok := g.underlying(f.typ).(*ast.ArrayType)
+	if !ok || slice.Len != nil {
+		return fmt.Errorf("len tag is not supported for %s", types.ExprString(f.typ))
+	}
+	et, _, ok := g.basic(slice.Elt)
+	byteSlice := ok && et.method == "Byte"
+	if !hostile {
+		g.printf("if n := int(v.%s); cap(%s) >= n {\n%s = %s[:n]\n} else {\n%s = make(%s, n)\n}\n",
+			lf.name, target, target, target, target, types.ExprString(f.typ))
+		if byteSlice {
+			g.printf("if err := r.ReadBytes(%s); err != nil {\n%serr\n}\n", target, fail)
+			return nil
+		}
+		g.printf("for i := range %s {\n", target)
+		if err = g.read(target+"[i]", slice.Elt, 1, fail); err != nil {
+			return err
+		}
+		g.printf("}\n")
+		return nil
+	}
+	g.printf("if n := int(v.%s); cap(%s) >= n {\n%s = %s[:n]\n", lf.name, target, target, target)
+	if byteSlice {
+		g.printf("if err := r.ReadBytes(%s); err != nil {\n%serr\n}\n", target, fail)
+		g.printf("} else {\n%s = %s[:0]\n", target, target)
+		g.readBytesGrowing(target, fail)
+		g.printf("}\n")
+		return nil
+	}
+	// other elements are appended in chunks of about 64 KiB as they are read
+	size, err := g.size(slice.Elt)
+	if err != nil {
+		return err
+	}
+	chunk := (64 << 10) / size
+	if chunk < 1 {
+		chunk = 1
+	}
+	g.printf("} else {\n%s = %s[:0]\n}\n", target, target)
+	g.printf("for i, n := 0, int(v.%s); i < n; i++ {\n", lf.name)
+	g.printf("if i == len(%s) {\nm := n - i\nif m > %d {\nm = %d\n}\n", target, chunk, chunk)
+	g.printf("%s = append(%s, make(%s, m)...)\n}\n", target, target, types.ExprString(&ast.ArrayType{Elt: slice.Elt}))
+	if err = g.read(target+"[i]", slice.Elt, 1, fail); err != nil {
+		return err
+	}
+	g.printf("}\n")
+	return nil
+}
+
+// readBytesGrowing generates the decoding of `n` bytes onto the end of the
+// byte slice `target`, appending them in chunks so that at most 64 KiB is
+// allocated beyond the bytes actually read.
+func (g *generator) readBytesGrowing(target, fail string) {
+	g.printf("for len(%s) < n {\nm := n - len(%s)\nif m > 64<<10 {\nm = 64 << 10\n}\n", target, target)
+	g.printf("%s = append(%s, make([]byte, m)...)\n", target, target)
+	g.printf("if err := r.ReadBytes(%s[len(%s)-m:]); err != nil {\n%serr\n}\n}\n", target, target, fail)
+}
+
+// read generates the decoding of the fixed-size value `target` of type `typ`.
+func (g *generator) read(target string, typ ast.Expr, depth int, fail string) error {
+	if bt, named, ok := g.basic(typ); ok {
+		if bt.name == "bool" {
+			g.printf("{\nc := byte(0)\nif err := r.ReadByte(&c); err != nil {\n%serr\n}\n%s = c != 0\n}\n", fail, target)
+			return nil
+		}
+		ptr := "&" + target
+		if named {
+			ptr = fmt.Sprintf("(*%s)(&%s)", bt.name, target)
+		}
+		g.printf("if err := r.Read%s(%s); err != nil {\n%serr\n}\n", bt.method, ptr, fail)
+		return nil
+	}
+	switch t := g.underlying(typ).(type) {
+	case *ast.Ident:
+		if g.generate[t.Name] {
+			g.printf("if err := %s.ReadFrom(r); err != nil {\n%serr\n}\n", target, fail)
+			return nil
+		}
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return fmt.Errorf("%s requires a len tag", types.ExprString(typ))
+		}
+		if bt, _, ok := g.basic(t.Elt); ok && bt.method == "Byte" {
+			g.printf("if err := r.ReadBytes(%s[:]); err != nil {\n%serr\n}\n", target, fail)
+			return nil
+		}
+		idx := index(depth)
+		g.printf("for %s := range %s {\n", idx, target)
+		if err := g.read(target+"["+idx+"]", t.Elt, depth+1, fail); err != nil {
+			return err
+		}
+		g.printf("}\n")
+		return nil
+	}
+	return unsupported(typ)
+}
+
+// genWriteTo generates the `WriteTo` method of `typeName`.
+func (g *generator) genWriteTo(typeName string, fields []field) error {
+	g.printf("\n// WriteTo encodes `v` to `w`, according to the current byte order of `w`.\n")
+	g.printf("func (v *%s) WriteTo(w *bin.Writer) error {\n", typeName)
+
+	// validate length fields up front, and record the field each is written from
+	lengths := map[string]string{}
+	for i, f := range fields {
+		if f.tag.ignore || f.tag.lenField == "" {
+			continue
+		}
+		lf, bt, err := g.lenField(typeName, fields, i, f.tag.lenField)
+		if err != nil {
+			return err
+		}
+		g.imports["fmt"] = true
+		if first, ok := lengths[lf.name]; ok {
+			g.printf("if len(v.%s) != len(v.%s) {\n", f.name, first)
+			g.printf("return fmt.Errorf(\"%s.%s: length %%d conflicts with %%d for length field %s\", len(v.%s), len(v.%s))\n}\n",
+				typeName, f.name, lf.name, f.name, first)
+			continue
+		}
+		lengths[lf.name] = f.name
+		conv := "uint64"
+		if bt.signed {
+			conv = "int64"
+		}
+		g.printf("if v.%s != 0 && %s(v.%s) != %s(len(v.%s)) {\n", lf.name, conv, lf.name, conv, f.name)
+		g.printf("return fmt.Errorf(\"%s.%s: length field %s is %%d, but length is %%d\", v.%s, len(v.%s))\n}\n",
+			typeName, f.name, lf.name, lf.name, f.name)
+		if bt.size < 8 {
+			max := uint64(1)<<uint(bt.size*8) - 1
+			if bt.signed {
+				max >>= 1
+			}
+			g.printf("if uint64(len(v.%s)) > %d {\n", f.name, max)
+			g.printf("return fmt.Errorf(\"%s.%s: length %%d overflows length field %s\", len(v.%s))\n}\n",
+				typeName, f.name, lf.name, f.name)
+		}
+	}
+
+	g.declareByteOrder(fields, "w")
+	for _, f := range fields {
+		if f.tag.ignore {
+			continue
+		}
+		if f.tag.skip > 0 {
+			g.printf("if err := w.ZeroFill(%d); err != nil {\nreturn err\n}\n", f.tag.skip)
+		}
+		if f.name == "_" {
+			size, err := g.size(f.typ)
+			if err != nil {
+				return fmt.Errorf("%s: blank field: %v", typeName, err)
+			}
+			g.printf("if err := w.ZeroFill(%d); err != nil {\nreturn err\n}\n", size)
+			continue
+		}
+		if !ast.IsExported(f.name) {
+			continue
+		}
+		fail := "return "
+		if f.tag.bo != "" {
+			g.printf("w.SetByteOrder(%s)\n", f.tag.bo)
+			fail = "w.SetByteOrder(bo)\nreturn "
+		}
+		var err error
+		if slice, ok := lengths[f.name]; ok {
+			bt, _, _ := g.basic(f.typ)
+			g.printf("if err := w.Write%s(%s(len(v.%s))); err != nil {\n%serr\n}\n", bt.method, bt.name, slice, fail)
+		} else if f.tag.lenField != "" {
+			err = g.writeSized("v."+f.name, f.typ, fail)
+		} else {
+			err = g.write("v."+f.name, f.typ, 0, fail)
+		}
+		if err != nil {
+			return fmt.Errorf("%s.%s: %v", typeName, f.name, err)
+		}
+		if f.tag.bo != "" {
+			g.printf("w.SetByteOrder(bo)\n")
+		}
+	}
+	g.printf("return nil\n}\n")
+	return nil
+}
+
+// writeSized generates the encoding of the slice or string `target`.
+func (g *generator) writeSized(target string, typ ast.Expr, fail string) error {
+	if ident, ok := typ.(*ast.Ident); ok && ident.Name == "string" {
+		g.printf("if err := w.WriteBytes([]byte(%s)); err != nil {\n%serr\n}\n", target, fail)
+		return nil
+	}
+	slice, ok := g.underlying(typ).(*ast.ArrayType)
+	if !ok || slice.Len != nil {
+		return fmt.Errorf("len tag is not supported for %s", types.ExprString(typ))
+	}
+	if bt, _, ok := g.basic(slice.Elt); ok && bt.method == "Byte" {
+		g.printf("if err := w.WriteBytes(%s); err != nil {\n%serr\n}\n", target, fail)
+		return nil
+	}
+	g.printf("for i := range %s {\n", target)
+	if err := g.write(target+"[i]", slice.Elt, 1, fail); err != nil {
+		return err
+	}
+	g.printf("}\n")
+	return nil
+}
+
+// write generates the encoding of the fixed-size value `target` of type `typ`.
+func (g *generator) write(target string, typ ast.Expr, depth int, fail string) error {
+	if bt, named, ok := g.basic(typ); ok {
+		if bt.name == "bool" {
+			g.printf("{\nc := byte(0)\nif %s {\nc = 1\n}\nif err := w.WriteByte(c); err != nil {\n%serr\n}\n}\n", target, fail)
+			return nil
+		}
+		value := target
+		if named {
+			value = fmt.Sprintf("%s(%s)", bt.name, target)
+		}
+		g.printf("if err := w.Write%s(%s); err != nil {\n%serr\n}\n", bt.method, value, fail)
+		return nil
+	}
+	switch t := g.underlying(typ).(type) {
+	case *ast.Ident:
+		if g.generate[t.Name] {
+			g.printf("if err := %s.WriteTo(w); err != nil {\n%serr\n}\n", target, fail)
+			return nil
+		}
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return fmt.Errorf("%s requires a len tag", types.ExprString(typ))
+		}
+		if bt, _, ok := g.basic(t.Elt); ok && bt.method == "Byte" {
+			g.printf("if err := w.WriteBytes(%s[:]); err != nil {\n%serr\n}\n", target, fail)
+			return nil
+		}
+		idx := index(depth)
+		g.printf("for %s := range %s {\n", idx, target)
+		if err := g.write(target+"["+idx+"]", t.Elt, depth+1, fail); err != nil {
+			return err
+		}
+		g.printf("}\n")
+		return nil
+	}
+	return unsupported(typ)
+}
+
+// declareByteOrder saves the byte order of `rw`, if any field overrides it.
+func (g *generator) declareByteOrder(fields []field, rw string) {
+	for _, f := range fields {
+		if !f.tag.ignore && f.tag.bo != "" && ast.IsExported(f.name) {
+			g.imports["encoding/binary"] = true
+			g.printf("bo := %s.GetByteOrder()\n", rw)
+			return
+		}
+	}
+}
+
+// index returns the name of the loop variable for arrays nested `depth` deep.
+func index(depth int) string {
+	if depth == 0 {
+		return "i"
+	}
+	return "i" + strconv.Itoa(depth)
+}
+
+// unsupported describes why `typ` cannot be (de)serialised.
+func unsupported(typ ast.Expr) error {
+	if ident, ok := typ.(*ast.Ident); ok {
+		switch ident.Name {
+		case "string":
+			return fmt.Errorf("string requires a len tag")
+		case "int", "uint", "uintptr":
+			return fmt.Errorf("%s is not fixed-size", ident.Name)
+		}
+		return fmt.Errorf("type %s must be a fixed-size type or be listed in -type", ident.Name)
+	}
+	return fmt.Errorf("unsupported type %s", types.ExprString(typ))
+}
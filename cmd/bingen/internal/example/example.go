@@ -0,0 +1,28 @@
+// Package example demonstrates the methods generated by bingen.
+package example
+
+//go:generate go run github.com/b71729/bin/cmd/bingen -type=Header,Entry
+
+// Kind identifies the type of an `Entry`.
+type Kind uint16
+
+// Header is an example container header.
+type Header struct {
+	Magic   [4]byte
+	Version uint16 `bin:"big"`
+	Flags   uint8
+	_       [3]byte
+	Offset  int64
+	Scale   float32
+	Valid   bool
+	Entries [2]Entry
+	DataLen uint32 `bin:"skip=2"`
+	Data    []byte `bin:"len=DataLen"`
+}
+
+// Entry is an example fixed-size record.
+type Entry struct {
+	Kind  Kind
+	Value float64
+	Grid  [2][2]int16
+}
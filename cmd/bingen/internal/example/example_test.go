@@ -0,0 +1,182 @@
+package example
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/b71729/bin"
+	"github.com/stretchr/testify/assert"
+)
+
+var testHeader = Header{
+	Magic:   [4]byte{'B', 'I', 'N', '!'},
+	Version: 0x0102,
+	Flags:   0x80,
+	Offset:  -123456789,
+	Scale:   123.456,
+	Valid:   true,
+	Entries: [2]Entry{
+		{Kind: 1, Value: 1.5, Grid: [2][2]int16{{1, -2}, {3, -4}}},
+		{Kind: 2, Value: -2.5, Grid: [2][2]int16{{5, -6}, {7, -8}}},
+	},
+	Data: []byte{0xDE, 0xAD, 0xBE, 0xEF},
+}
+
+// loopReader endlessly repeats the contents of a buffer.
+type loopReader struct {
+	buf []byte
+	off int
+}
+
+func (l *loopReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		c := copy(p[n:], l.buf[l.off:])
+		n += c
+		l.off = (l.off + c) % len(l.buf)
+	}
+	return n, nil
+}
+
+type discard int
+
+func (discard) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func TestGeneratedMatchesReflection(t *testing.T) {
+	t.Parallel()
+	for _, bo := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		// WriteTo produces the same bytes as WriteStruct
+		generated := bytes.NewBuffer([]byte{})
+		w := bin.NewWriter(generated, bo)
+		v := testHeader
+		assert.NoError(t, v.WriteTo(&w))
+		reflected := bytes.NewBuffer([]byte{})
+		w = bin.NewWriter(reflected, bo)
+		assert.NoError(t, w.WriteStruct(testHeader))
+		assert.Equal(t, reflected.Bytes(), generated.Bytes())
+		// length fields are filled in without modifying the value
+		assert.Equal(t, uint32(0), v.DataLen)
+
+		// ReadFrom decodes the same value as ReadStruct
+		r := bin.NewReaderBytes(generated.Bytes(), bo)
+		got := Header{}
+		assert.NoError(t, got.ReadFrom(&r))
+		assert.Equal(t, int64(generated.Len()), r.GetPosition())
+		assert.Equal(t, bo, r.GetByteOrder())
+		r = bin.NewReaderBytes(generated.Bytes(), bo)
+		expected := Header{}
+		assert.NoError(t, r.ReadStruct(&expected))
+		assert.Equal(t, expected, got)
+		assert.Equal(t, uint32(len(testHeader.Data)), got.DataLen)
+	}
+}
+
+func TestGeneratedError(t *testing.T) {
+	t.Parallel()
+	// length field mismatch
+	v := testHeader
+	v.DataLen = 1
+	w := bin.NewWriter(discard(0), binary.LittleEndian)
+	assert.Error(t, v.WriteTo(&w))
+
+	// truncated input at every possible offset
+	out := bytes.NewBuffer([]byte{})
+	w = bin.NewWriter(out, binary.LittleEndian)
+	assert.NoError(t, w.WriteStruct(testHeader))
+	for i := 0; i < out.Len(); i++ {
+		r := bin.NewReaderBytes(out.Bytes()[:i], binary.LittleEndian)
+		got := Header{}
+		assert.Error(t, got.ReadFrom(&r), "truncated at %d", i)
+		assert.Equal(t, binary.LittleEndian, r.GetByteOrder())
+	}
+}
+
+func TestGeneratedHostileLength(t *testing.T) {
+	t.Parallel()
+	// a length far beyond the input fails as truncated, as with ReadStruct,
+	// rather than allocating for the whole length
+	out := bytes.NewBuffer([]byte{})
+	w := bin.NewWriter(out, binary.LittleEndian)
+	assert.NoError(t, w.WriteStruct(testHeader))
+	src := out.Bytes()
+	binary.LittleEndian.PutUint32(src[len(src)-len(testHeader.Data)-4:], 0xFFFFFFFF)
+	r := bin.NewReaderBytes(src, binary.LittleEndian)
+	got := Header{}
+	assert.True(t, errors.Is(got.ReadFrom(&r), io.ErrUnexpectedEOF))
+	r = bin.NewReaderBytes(src, binary.LittleEndian)
+	assert.True(t, errors.Is(r.ReadStruct(&got), io.ErrUnexpectedEOF))
+}
+
+func TestGeneratedAllocs(t *testing.T) {
+	out := bytes.NewBuffer([]byte{})
+	w := bin.NewWriter(out, binary.LittleEndian)
+	assert.NoError(t, w.WriteStruct(testHeader))
+	r := bin.NewReader(&loopReader{buf: out.Bytes()}, binary.LittleEndian)
+	v := Header{Data: make([]byte, 0, 16)}
+	assert.Equal(t, float64(0), testing.AllocsPerRun(100, func() {
+		if err := v.ReadFrom(&r); err != nil {
+			panic(err)
+		}
+	}))
+	w = bin.NewWriter(discard(0), binary.LittleEndian)
+	assert.Equal(t, float64(0), testing.AllocsPerRun(100, func() {
+		if err := v.WriteTo(&w); err != nil {
+			panic(err)
+		}
+	}))
+}
+
+func BenchmarkReadFrom(b *testing.B) {
+	out := bytes.NewBuffer([]byte{})
+	w := bin.NewWriter(out, binary.LittleEndian)
+	w.WriteStruct(testHeader)
+	r := bin.NewReader(&loopReader{buf: out.Bytes()}, binary.LittleEndian)
+	v := Header{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := v.ReadFrom(&r); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func BenchmarkReadStruct(b *testing.B) {
+	out := bytes.NewBuffer([]byte{})
+	w := bin.NewWriter(out, binary.LittleEndian)
+	w.WriteStruct(testHeader)
+	r := bin.NewReader(&loopReader{buf: out.Bytes()}, binary.LittleEndian)
+	v := Header{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := r.ReadStruct(&v); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func BenchmarkWriteTo(b *testing.B) {
+	w := bin.NewWriter(discard(0), binary.LittleEndian)
+	v := testHeader
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := v.WriteTo(&w); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func BenchmarkWriteStruct(b *testing.B) {
+	w := bin.NewWriter(discard(0), binary.LittleEndian)
+	v := testHeader
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := w.WriteStruct(&v); err != nil {
+			panic(err)
+		}
+	}
+}
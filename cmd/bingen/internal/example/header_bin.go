@@ -0,0 +1,168 @@
+// Code generated by "bingen -type=Header,Entry"; DO NOT EDIT.
+
+package example
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/b71729/bin"
+)
+
+// ReadFrom decodes `v` from `r`, according to the current byte order of `r`.
+func (v *Header) ReadFrom(r *bin.Reader) error {
+	bo := r.GetByteOrder()
+	if err := r.ReadBytes(v.Magic[:]); err != nil {
+		return err
+	}
+	r.SetByteOrder(binary.BigEndian)
+	if err := r.ReadUint16(&v.Version); err != nil {
+		r.SetByteOrder(bo)
+		return err
+	}
+	r.SetByteOrder(bo)
+	if err := r.ReadByte(&v.Flags); err != nil {
+		return err
+	}
+	if err := r.Discard(3); err != nil {
+		return err
+	}
+	if err := r.ReadInt64(&v.Offset); err != nil {
+		return err
+	}
+	if err := r.ReadFloat32(&v.Scale); err != nil {
+		return err
+	}
+	{
+		c := byte(0)
+		if err := r.ReadByte(&c); err != nil {
+			return err
+		}
+		v.Valid = c != 0
+	}
+	for i := range v.Entries {
+		if err := v.Entries[i].ReadFrom(r); err != nil {
+			return err
+		}
+	}
+	if err := r.Discard(2); err != nil {
+		return err
+	}
+	if err := r.ReadUint32(&v.DataLen); err != nil {
+		return err
+	}
+	if n := int(v.DataLen); n < 0 || uint64(n) != uint64(v.DataLen) {
+		return fmt.Errorf("Header.Data: length %d: %w", v.DataLen, bin.ErrTooLong)
+	}
+	if n := int(v.DataLen); cap(v.Data) >= n {
+		v.Data = v.Data[:n]
+		if err := r.ReadBytes(v.Data); err != nil {
+			return err
+		}
+	} else {
+		v.Data = v.Data[:0]
+		for len(v.Data) < n {
+			m := n - len(v.Data)
+			if m > 64<<10 {
+				m = 64 << 10
+			}
+			v.Data = append(v.Data, make([]byte, m)...)
+			if err := r.ReadBytes(v.Data[len(v.Data)-m:]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteTo encodes `v` to `w`, according to the current byte order of `w`.
+func (v *Header) WriteTo(w *bin.Writer) error {
+	if v.DataLen != 0 && uint64(v.DataLen) != uint64(len(v.Data)) {
+		return fmt.Errorf("Header.Data: length field DataLen is %d, but length is %d", v.DataLen, len(v.Data))
+	}
+	if uint64(len(v.Data)) > 4294967295 {
+		return fmt.Errorf("Header.Data: length %d overflows length field DataLen", len(v.Data))
+	}
+	bo := w.GetByteOrder()
+	if err := w.WriteBytes(v.Magic[:]); err != nil {
+		return err
+	}
+	w.SetByteOrder(binary.BigEndian)
+	if err := w.WriteUint16(v.Version); err != nil {
+		w.SetByteOrder(bo)
+		return err
+	}
+	w.SetByteOrder(bo)
+	if err := w.WriteByte(v.Flags); err != nil {
+		return err
+	}
+	if err := w.ZeroFill(3); err != nil {
+		return err
+	}
+	if err := w.WriteInt64(v.Offset); err != nil {
+		return err
+	}
+	if err := w.WriteFloat32(v.Scale); err != nil {
+		return err
+	}
+	{
+		c := byte(0)
+		if v.Valid {
+			c = 1
+		}
+		if err := w.WriteByte(c); err != nil {
+			return err
+		}
+	}
+	for i := range v.Entries {
+		if err := v.Entries[i].WriteTo(w); err != nil {
+			return err
+		}
+	}
+	if err := w.ZeroFill(2); err != nil {
+		return err
+	}
+	if err := w.WriteUint32(uint32(len(v.Data))); err != nil {
+		return err
+	}
+	if err := w.WriteBytes(v.Data); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ReadFrom decodes `v` from `r`, according to the current byte order of `r`.
+func (v *Entry) ReadFrom(r *bin.Reader) error {
+	if err := r.ReadUint16((*uint16)(&v.Kind)); err != nil {
+		return err
+	}
+	if err := r.ReadFloat64(&v.Value); err != nil {
+		return err
+	}
+	for i := range v.Grid {
+		for i1 := range v.Grid[i] {
+			if err := r.ReadInt16(&v.Grid[i][i1]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteTo encodes `v` to `w`, according to the current byte order of `w`.
+func (v *Entry) WriteTo(w *bin.Writer) error {
+	if err := w.WriteUint16(uint16(v.Kind)); err != nil {
+		return err
+	}
+	if err := w.WriteFloat64(v.Value); err != nil {
+		return err
+	}
+	for i := range v.Grid {
+		for i1 := range v.Grid[i] {
+			if err := w.WriteInt16(v.Grid[i][i1]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// Command bingen generates zero-reflection `ReadFrom` and `WriteTo` methods
+// for structs, such that they can be decoded from a `bin.Reader` and encoded
+// to a `bin.Writer` without the overhead of `ReadStruct` and `WriteStruct`.
+//
+// Fields are (de)serialised in declaration order, and the same field kinds and
+// `bin` struct tags as `ReadStruct` and `WriteStruct` are understood:
+//
+//	"-"         the field is ignored
+//	big, be     the field is big endian, regardless of the current byte order
+//	little, le  the field is little endian, regardless of the current byte order
+//	skip=N      N bytes of padding precede the field
+//	len=Field   the length of a slice or string is held in the sibling
+//	            integer field `Field`, which must precede it
+//
+// Nested structs must also be listed in `-type`. Typically, bingen is invoked
+// via `go generate`:
+//
+//	//go:generate bingen -type=Header,Entry
+//
+// which writes the methods to `header_bin.go` in the package directory.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	typeNames = flag.String("type", "", "comma-separated list of struct type names; must be set")
+	output    = flag.String("output", "", "output file name; default <dir>/<type>_bin.go")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage of bingen:\n")
+	fmt.Fprintf(os.Stderr, "\tbingen -type T[,T...] [-output file] [directory]\n")
+	fmt.Fprintf(os.Stderr, "Flags:\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("bingen: ")
+	flag.Usage = usage
+	flag.Parse()
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	types := strings.Split(*typeNames, ",")
+
+	src, err := generateDir(dir, types)
+	if err != nil {
+		log.Fatal(err)
+	}
+	name := *output
+	if name == "" {
+		name = filepath.Join(dir, strings.ToLower(types[0])+"_bin.go")
+	}
+	if err = ioutil.WriteFile(name, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// parseSource parses a single file of Go source.
+func parseSource(t *testing.T, filename string, src interface{}) []*ast.File {
+	f, err := parser.ParseFile(token.NewFileSet(), filename, src, 0)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return []*ast.File{f}
+}
+
+func TestGenerateGolden(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		input string
+		types []string
+	}{
+		{"header", []string{"Header", "Inner"}},
+		{"named", []string{"Entry", "Table"}},
+		{"lengths", []string{"Lengths", "Item"}},
+	}
+	for _, c := range cases {
+		input := filepath.Join("testdata", c.input+".go")
+		golden := filepath.Join("testdata", c.input+".golden")
+		got, err := generate(parseSource(t, input, nil), c.types)
+		assert.NoError(t, err, input)
+		if *update {
+			assert.NoError(t, ioutil.WriteFile(golden, got, 0644))
+			continue
+		}
+		expected, err := ioutil.ReadFile(golden)
+		assert.NoError(t, err)
+		assert.Equal(t, string(expected), string(got), golden)
+	}
+}
+
+func TestGenerateDir(t *testing.T) {
+	t.Parallel()
+	// the checked-in example must be up to date
+	dir := filepath.Join("internal", "example")
+	got, err := generateDir(dir, []string{"Header", "Entry"})
+	assert.NoError(t, err)
+	expected, err := ioutil.ReadFile(filepath.Join(dir, "header_bin.go"))
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), string(got))
+}
+
+func TestGenerateDirError(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "bingen")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	// no Go files
+	_, err = generateDir(dir, []string{"T"})
+	assert.Error(t, err)
+
+	// multiple packages
+	_, err = generateDir("testdata", []string{"Header"})
+	assert.Error(t, err)
+
+	// invalid source
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\ntype"), 0644))
+	_, err = generateDir(dir, []string{"T"})
+	assert.Error(t, err)
+}
+
+func TestGenerateError(t *testing.T) {
+	t.Parallel()
+	invalid := map[string]string{
+		"missing":        `type U struct{}`,
+		"not a struct":   `type T uint16`,
+		"embedded":       `type T struct{ U }; type U struct{}`,
+		"unsized int":    `type T struct{ A int }`,
+		"slice":          `type T struct{ A []byte }`,
+		"string":         `type T struct{ A string }`,
+		"map":            `type T struct{ A map[int]int }`,
+		"foreign":        `type T struct{ A time.Time }`,
+		"not listed":     `type T struct{ A U }; type U struct{}`,
+		"unknown option": "type T struct{ A uint8 `bin:\"bogus\"` }",
+		"bad skip":       "type T struct{ A uint8 `bin:\"skip=x\"` }",
+		"empty len":      "type T struct{ A []byte `bin:\"len=\"` }",
+		"len follows":    "type T struct{ A []byte `bin:\"len=N\"`; N uint8 }",
+		"len not int":    "type T struct{ N float32; A []byte `bin:\"len=N\"` }",
+		"len of array":   "type T struct{ N uint8; A [2]byte `bin:\"len=N\"` }",
+		"blank slice":    `type T struct{ _ []byte }`,
+		"blank len":      `type T struct{ _ [N]byte }`,
+		"nested slice":   `type T struct{ A [2][]byte }`,
+		"slice of slice": "type T struct{ N uint8; A [][]byte `bin:\"len=N\"` }",
+	}
+	for name, src := range invalid {
+		files := parseSource(t, name, "package p\n"+src)
+		_, err := generate(files, []string{"T"})
+		assert.Error(t, err, name)
+	}
+}
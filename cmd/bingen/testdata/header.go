@@ -0,0 +1,27 @@
+package header
+
+// Header exercises each supported field kind and tag option.
+type Header struct {
+	Magic   [4]byte
+	Version uint16 `bin:"big"`
+	Flags   uint8
+	_       [1]byte
+	Offset  int32 `bin:"le"`
+	Scale   float32
+	Ratio   float64 `bin:"skip=2"`
+	Valid   bool
+	Inner   Inner
+	Pairs   [2]Inner
+	Count   uint8
+	Items   []uint16 `bin:"len=Count"`
+	DataLen uint16
+	Data    []byte `bin:"len=DataLen"`
+	Ignored int    `bin:"-"`
+	private uint32
+}
+
+// Inner is nested within Header.
+type Inner struct {
+	A int16
+	B [2]uint8
+}
@@ -0,0 +1,18 @@
+package lengths
+
+// Lengths exercises length fields wide enough to exceed an int, or the
+// memory available, if hostile.
+type Lengths struct {
+	DataLen  uint64
+	Data     []byte `bin:"len=DataLen"`
+	NameLen  int64
+	Name     string `bin:"len=NameLen"`
+	ItemsLen uint32
+	Items    []Item `bin:"len=ItemsLen"`
+}
+
+// Item is an element of Lengths.
+type Item struct {
+	A uint16
+	B [2]float32
+}
@@ -0,0 +1,26 @@
+package named
+
+// Kind is a named integer type.
+type Kind uint16
+
+// Grid is a named array type.
+type Grid [2][2]int8
+
+// Entries is a named slice type.
+type Entries []Entry
+
+// Entry holds named types, with a per-field byte order.
+type Entry struct {
+	Kind  Kind `bin:"be"`
+	Grid  Grid
+	Valid [3]bool
+}
+
+// Table holds signed length fields shared between several fields.
+type Table struct {
+	N       int8
+	Entries Entries `bin:"len=N"`
+	Kinds   []Kind  `bin:"len=N,little"`
+	NameLen Kind
+	Name    string `bin:"len=NameLen"`
+}
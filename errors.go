@@ -0,0 +1,121 @@
+package bin
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+/*
+===============================================================================
+    Data Types
+===============================================================================
+*/
+
+var (
+	// ErrNilSource is returned when a reader has no source, or a writer has
+	// no destination.
+	ErrNilSource = errors.New("bin: source is nil")
+
+	// ErrNilByteOrder is returned when a multi-byte value is read or written
+	// without a byte order having been set.
+	ErrNilByteOrder = errors.New("bin: byte order is not set")
+
+	// ErrNegativeLength is returned when a length or count is negative.
+	ErrNegativeLength = errors.New("bin: negative length")
+
+	// ErrTooLong is returned when a length exceeds the permitted maximum, such
+	// as the maximum length of a varint, the `max` of
+	// `Reader.ReadPrefixedBytes` or the capacity of a prefix.
+	ErrTooLong = errors.New("bin: length exceeds maximum")
+
+	// ErrOverflow is returned when a value cannot be represented in the width
+	// it is to be written with, such as a value of `Writer.WriteUint24`
+	// exceeding 24 bits.
+	ErrOverflow = errors.New("bin: value overflows width")
+
+	// ErrInvalidEncoding is returned when text cannot be decoded or encoded
+	// in the requested character encoding, such as a non-ASCII byte read by
+	// `Reader.ReadASCII`, or a string containing a null-byte given to
+	// `Writer.WriteCString`.
+	ErrInvalidEncoding = errors.New("bin: invalid text encoding")
+
+	// ErrNonZeroPadding is returned by `Reader.AlignZero` when alignment
+	// padding contains a byte other than zero.
+	ErrNonZeroPadding = errors.New("bin: padding is not zero")
+
+	// ErrChecksumMismatch is returned when a checksum or digest read from the
+	// stream differs from that computed over the data it covers, such as by
+	// `Reader.ExpectCRC32`.
+	ErrChecksumMismatch = errors.New("bin: checksum mismatch")
+
+	// ErrExceededContainer is returned when a read from a sub-reader (see
+	// `Reader.SubReader`) would extend beyond the bounds of its container.
+	ErrExceededContainer = errors.New("bin: read exceeds container bounds")
+
+	errVarintOverflow = errors.New("bin: varint overflows a 64-bit integer")
+	errNotSeeker      = errors.New("bin: source does not implement io.Seeker")
+	errNotSubReader   = errors.New("bin: reader is not a sub-reader")
+	errTooManyBits    = errors.New("bin: cannot read or write more than 64 bits")
+	errBadPrefix      = errors.New("bin: invalid prefix width")
+	errBadAlignment   = errors.New("bin: alignment must be positive")
+	errPatchSize      = errors.New("bin: patch does not match reservation size")
+	errStaleReserve   = errors.New("bin: reservation already patched or discarded")
+	errNoHash         = errors.New("bin: no hash has been begun")
+	errNotHash32      = errors.New("bin: hash is not a hash.Hash32")
+	errBadWidth       = errors.New("bin: width must be between 1 and 8 bytes")
+	errNoMark         = errors.New("bin: no mark has been set")
+	errMarkLost       = errors.New("bin: mark was invalidated by a seek or the retention limit")
+)
+
+// Error describes a failed operation of a `Reader`, `Writer`, `ReaderAt`,
+// `BitReader` or `BitWriter`. The cause, `Err`, is either one of the sentinel
+// errors of this package or the error returned by the underlying source or
+// destination (such as `io.ErrUnexpectedEOF`), and may be tested for with
+// `errors.Is`:
+//
+//	if errors.Is(err, io.ErrUnexpectedEOF) {
+//		var e *bin.Error
+//		errors.As(err, &e)
+//		log.Printf("truncated %s at offset %d", e.Op, e.Offset)
+//	}
+type Error struct {
+	Op        string // the failed method, such as "ReadUint32"
+	Offset    int64  // position at which the operation began
+	Requested int    // number of bytes requested; zero unless I/O failed
+	Got       int    // number of bytes actually read or written
+	Err       error  // the cause
+}
+
+// Error satisfies the `error` interface.
+func (e *Error) Error() string {
+	s := "bin: " + e.Op + " at offset " + strconv.FormatInt(e.Offset, 10)
+	if e.Requested > 0 {
+		s += " (" + strconv.Itoa(e.Got) + " of " + strconv.Itoa(e.Requested) + " bytes)"
+	}
+	if e.Err == nil {
+		return s
+	}
+	return s + ": " + strings.TrimPrefix(e.Err.Error(), "bin: ")
+}
+
+// Unwrap returns the cause of the error, for use with `errors.Is` and
+// `errors.As`.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// newError returns an `*Error` for the operation `op` which failed at the
+// current position due to `err`.
+func (b *binaryBase) newError(op string, err error) error {
+	return &Error{Op: op, Offset: b.pos, Err: err}
+}
+
+// wrapError returns `err` as an `*Error` for the operation `op` beginning at
+// `offset`, unless it already is one (such as when returned by a nested call).
+func wrapError(op string, offset int64, err error) error {
+	if _, ok := err.(*Error); ok {
+		return err
+	}
+	return &Error{Op: op, Offset: offset, Err: err}
+}
@@ -0,0 +1,247 @@
+package bin
+
+import (
+	"math"
+	"unsafe"
+)
+
+/*
+===============================================================================
+    Reader
+===============================================================================
+*/
+
+// ReadFloat16 reads an IEEE 754 half-precision (16-bit) floating-point number
+// into `dst` according to the current byte order. Every half-precision value,
+// including subnormals and NaN payloads, is exactly representable as a
+// float32.
+func (b *Reader) ReadFloat16(dst *float32) error {
+	return b.readHalf("ReadFloat16", dst, float16to32)
+}
+
+// ReadBFloat16 reads a bfloat16 ("brain" floating-point) number, which is
+// the upper half of a float32, into `dst` according to the current byte
+// order.
+func (b *Reader) ReadBFloat16(dst *float32) error {
+	return b.readHalf("ReadBFloat16", dst, bfloat16to32)
+}
+
+// ReadFloat16s reads `len(dst)` half-precision floating-point numbers into
+// `dst` according to the current byte order. As with `ReadUint16s`, the
+// bytes are read with a single `ReadBytes`, here into the memory of `dst`,
+// and are then widened in place. If an error is returned, the contents of
+// `dst` are unspecified.
+func (b *Reader) ReadFloat16s(dst []float32) error {
+	return b.readHalfs("ReadFloat16s", dst, float16to32)
+}
+
+// ReadBFloat16s reads `len(dst)` bfloat16 numbers into `dst` according to
+// the current byte order. See `ReadFloat16s`.
+func (b *Reader) ReadBFloat16s(dst []float32) error {
+	return b.readHalfs("ReadBFloat16s", dst, bfloat16to32)
+}
+
+// readHalf is the implementation of `ReadFloat16` and `ReadBFloat16`.
+func (b *Reader) readHalf(op string, dst *float32, conv func(uint16) float32) error {
+	if b.source == nil {
+		return b.newError(op, ErrNilSource)
+	}
+	if b.bo == nil {
+		return b.newError(op, ErrNilByteOrder)
+	}
+	if err := b.readFull(op, b._1kb[:2]); err != nil {
+		return err
+	}
+	*dst = conv(b.bo.Uint16(b._1kb[:2]))
+	return nil
+}
+
+// readHalfs is the implementation of `ReadFloat16s` and `ReadBFloat16s`.
+func (b *Reader) readHalfs(op string, dst []float32, conv func(uint16) float32) error {
+	if b.source == nil {
+		return b.newError(op, ErrNilSource)
+	}
+	if b.bo == nil {
+		return b.newError(op, ErrNilByteOrder)
+	}
+	if len(dst) == 0 {
+		return nil
+	}
+	// the encoded values occupy the first half of `dst`
+	p := bytesOf(unsafe.Pointer(&dst), 2)
+	if err := b.readFull(op, p); err != nil {
+		return err
+	}
+	// widening from the end never overwrites a value yet to be read
+	for i := len(dst) - 1; i >= 0; i-- {
+		dst[i] = conv(b.bo.Uint16(p[i*2:]))
+	}
+	return nil
+}
+
+/*
+===============================================================================
+    Writer
+===============================================================================
+*/
+
+// WriteFloat16 writes `src` as an IEEE 754 half-precision (16-bit)
+// floating-point number according to the current byte order. It is rounded
+// to the nearest representable value, ties to even: values too large become
+// infinities, values too small become subnormals or zero, and NaNs remain
+// NaNs, keeping as much of their payload as fits.
+func (b *Writer) WriteFloat16(src float32) error {
+	return b.writeHalf("WriteFloat16", src, float32to16)
+}
+
+// WriteBFloat16 writes `src` as a bfloat16 number according to the current
+// byte order, rounding as `WriteFloat16`.
+func (b *Writer) WriteBFloat16(src float32) error {
+	return b.writeHalf("WriteBFloat16", src, float32toB16)
+}
+
+// WriteFloat16s writes each number of `src` as a half-precision
+// floating-point number according to the current byte order, converting in
+// batches through a temporary buffer. See `WriteFloat16`.
+func (b *Writer) WriteFloat16s(src []float32) error {
+	return b.writeHalfs("WriteFloat16s", src, float32to16)
+}
+
+// WriteBFloat16s writes each number of `src` as a bfloat16 number according
+// to the current byte order. See `WriteFloat16s`.
+func (b *Writer) WriteBFloat16s(src []float32) error {
+	return b.writeHalfs("WriteBFloat16s", src, float32toB16)
+}
+
+// writeHalf is the implementation of `WriteFloat16` and `WriteBFloat16`.
+func (b *Writer) writeHalf(op string, src float32, conv func(float32) uint16) error {
+	if b.dest == nil {
+		return b.newError(op, ErrNilSource)
+	}
+	if b.bo == nil {
+		return b.newError(op, ErrNilByteOrder)
+	}
+	b.bo.PutUint16(b._1kb[:2], conv(src))
+	return b.writeFull(op, b._1kb[:2])
+}
+
+// writeHalfs is the implementation of `WriteFloat16s` and `WriteBFloat16s`.
+func (b *Writer) writeHalfs(op string, src []float32, conv func(float32) uint16) error {
+	if b.dest == nil {
+		return b.newError(op, ErrNilSource)
+	}
+	if b.bo == nil {
+		return b.newError(op, ErrNilByteOrder)
+	}
+	start, size := b.pos, len(src)*2
+	for len(src) > 0 {
+		n := len(b._1kb) / 2
+		if len(src) < n {
+			n = len(src)
+		}
+		for i, v := range src[:n] {
+			b.bo.PutUint16(b._1kb[i*2:], conv(v))
+		}
+		if err := b.write(b._1kb[:n*2]); err != nil {
+			return &Error{Op: op, Offset: start, Requested: size, Got: int(b.pos - start), Err: err}
+		}
+		src = src[n:]
+	}
+	return nil
+}
+
+/*
+===============================================================================
+    Conversion
+===============================================================================
+*/
+
+// float16to32 widens the half-precision number `h` to a float32.
+func float16to32(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h & 0x3ff)
+	switch {
+	case exp == 0x1f:
+		// infinity, or NaN with its payload
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	case exp == 0 && mant == 0:
+		return math.Float32frombits(sign)
+	case exp == 0:
+		// subnormal: normalise, as all are normal in float32
+		exp = 127 - 15 + 1
+		for mant&0x400 == 0 {
+			mant <<= 1
+			exp--
+		}
+		return math.Float32frombits(sign | exp<<23 | (mant&0x3ff)<<13)
+	}
+	return math.Float32frombits(sign | (exp+127-15)<<23 | mant<<13)
+}
+
+// float32to16 narrows `f` to a half-precision number, rounding to nearest
+// with ties to even.
+func float32to16(f float32) uint16 {
+	x := math.Float32bits(f)
+	sign := uint16(x>>16) & 0x8000
+	exp := int(x>>23) & 0xff
+	mant := x & 0x7fffff
+	if exp == 0xff {
+		if mant == 0 {
+			return sign | 0x7c00
+		}
+		// keep the upper payload, ensuring the result is still a NaN
+		m := uint16(mant >> 13)
+		if m == 0 {
+			m = 0x200
+		}
+		return sign | 0x7c00 | m
+	}
+	e := exp - 127 + 15
+	switch {
+	case e >= 0x1f:
+		return sign | 0x7c00
+	case e < -10:
+		// less than half the smallest subnormal
+		return sign
+	case e <= 0:
+		// subnormal, in units of 2^-24; rounding up to 0x400 yields the
+		// smallest normal
+		m := mant | 0x800000
+		shift := uint(14 - e)
+		h := m >> shift
+		rem, half := m&(1<<shift-1), uint32(1)<<(shift-1)
+		if rem > half || rem == half && h&1 == 1 {
+			h++
+		}
+		return sign | uint16(h)
+	}
+	h := uint32(e)<<10 | mant>>13
+	// a carry out of the mantissa correctly increments the exponent, and
+	// may overflow to infinity
+	if rem := mant & 0x1fff; rem > 0x1000 || rem == 0x1000 && h&1 == 1 {
+		h++
+	}
+	return sign | uint16(h)
+}
+
+// bfloat16to32 widens the bfloat16 number `h` to a float32.
+func bfloat16to32(h uint16) float32 {
+	return math.Float32frombits(uint32(h) << 16)
+}
+
+// float32toB16 narrows `f` to a bfloat16 number, rounding to nearest with
+// ties to even.
+func float32toB16(f float32) uint16 {
+	x := math.Float32bits(f)
+	if x&0x7fffffff > 0x7f800000 {
+		// keep the upper payload, ensuring the result is still a NaN
+		h := uint16(x >> 16)
+		if h&0x7f == 0 {
+			h |= 0x40
+		}
+		return h
+	}
+	x += 0x7fff + (x>>16)&1
+	return uint16(x >> 16)
+}
@@ -0,0 +1,156 @@
+package bin
+
+import (
+	"bytes"
+	"fmt"
+	"hash"
+)
+
+/*
+===============================================================================
+    Reader
+===============================================================================
+*/
+
+// ExpectCRC32 ends the most recent hash (see `BeginHash`), which must be a
+// `hash.Hash32` such as that of `hash/crc32` or `hash/adler32`, and reads a
+// 32-bit checksum according to the current byte order. If the two differ, an
+// error wrapping `ErrChecksumMismatch` is returned whose `Offset` is that of
+// the checksum.
+//
+//	br.BeginHash(crc32.NewIEEE())
+//	br.ReadStruct(&header)
+//	if err := br.ExpectCRC32(); err != nil {
+//		return err
+//	}
+func (b *Reader) ExpectCRC32() error {
+	if b.source == nil {
+		return b.newError("ExpectCRC32", ErrNilSource)
+	}
+	if b.bo == nil {
+		return b.newError("ExpectCRC32", ErrNilByteOrder)
+	}
+	h, err := b.endHash32("ExpectCRC32")
+	if err != nil {
+		return err
+	}
+	start := b.pos
+	if err := b.readFull("ExpectCRC32", b._1kb[:4]); err != nil {
+		return err
+	}
+	if got, want := b.bo.Uint32(b._1kb[:4]), h.Sum32(); got != want {
+		return &Error{Op: "ExpectCRC32", Offset: start, Err: fmt.Errorf("%w: read %#08x, computed %#08x", ErrChecksumMismatch, got, want)}
+	}
+	return nil
+}
+
+// ExpectSum ends the most recent hash (see `BeginHash`) and reads a digest of
+// its size, such as the 32 bytes of a SHA-256 digest. If the two differ, an
+// error wrapping `ErrChecksumMismatch` is returned whose `Offset` is that of
+// the digest.
+func (b *Reader) ExpectSum() error {
+	if b.source == nil {
+		return b.newError("ExpectSum", ErrNilSource)
+	}
+	h := b.EndHash()
+	if h == nil {
+		return b.newError("ExpectSum", errNoHash)
+	}
+	got := b._1kb[:h.Size()]
+	start := b.pos
+	if err := b.readFull("ExpectSum", got); err != nil {
+		return err
+	}
+	if want := h.Sum(b._1kb[len(got):len(got)]); !bytes.Equal(got, want) {
+		return &Error{Op: "ExpectSum", Offset: start, Err: fmt.Errorf("%w: read %x, computed %x", ErrChecksumMismatch, got, want)}
+	}
+	return nil
+}
+
+/*
+===============================================================================
+    Writer
+===============================================================================
+*/
+
+// WriteCRC32 ends the most recent hash (see `BeginHash`), which must be a
+// `hash.Hash32` such as that of `hash/crc32` or `hash/adler32`, and writes
+// its 32-bit checksum according to the current byte order.
+func (b *Writer) WriteCRC32() error {
+	if b.dest == nil {
+		return b.newError("WriteCRC32", ErrNilSource)
+	}
+	if b.bo == nil {
+		return b.newError("WriteCRC32", ErrNilByteOrder)
+	}
+	h, err := b.endHash32("WriteCRC32")
+	if err != nil {
+		return err
+	}
+	b.bo.PutUint32(b._1kb[:4], h.Sum32())
+	return b.writeFull("WriteCRC32", b._1kb[:4])
+}
+
+// WriteSum ends the most recent hash (see `BeginHash`) and writes its digest.
+func (b *Writer) WriteSum() error {
+	if b.dest == nil {
+		return b.newError("WriteSum", ErrNilSource)
+	}
+	h := b.EndHash()
+	if h == nil {
+		return b.newError("WriteSum", errNoHash)
+	}
+	return b.writeFull("WriteSum", h.Sum(b._1kb[:0]))
+}
+
+/*
+===============================================================================
+    binaryBase
+===============================================================================
+*/
+
+// BeginHash begins hashing a region: every byte subsequently read or written
+// is also written to `h`, until `EndHash` is called. Hashes may be nested, in
+// which case bytes are written to each of them.
+//
+// For a `Reader`, only consumed bytes are hashed: bytes which have been
+// peeked but not yet consumed are excluded until they are, and those skipped
+// by `Seek` are excluded entirely. For a `Writer`, bytes are hashed as they
+// are written, so patching a reservation (see `Writer.Reserve`) within the
+// region is not reflected by the hash.
+func (b *binaryBase) BeginHash(h hash.Hash) {
+	b.hashes = append(b.hashes, h)
+}
+
+// EndHash ends the most recent hash begun with `BeginHash`, returning it, or
+// nil if there is none.
+func (b *binaryBase) EndHash() hash.Hash {
+	if len(b.hashes) == 0 {
+		return nil
+	}
+	h := b.hashes[len(b.hashes)-1]
+	b.hashes[len(b.hashes)-1] = nil
+	b.hashes = b.hashes[:len(b.hashes)-1]
+	return h
+}
+
+// endHash32 ends the most recent hash, which must be a `hash.Hash32`, for
+// the operation `op`. The hash is not ended if it is of another kind.
+func (b *binaryBase) endHash32(op string) (hash.Hash32, error) {
+	if len(b.hashes) == 0 {
+		return nil, b.newError(op, errNoHash)
+	}
+	h, ok := b.hashes[len(b.hashes)-1].(hash.Hash32)
+	if !ok {
+		return nil, b.newError(op, errNotHash32)
+	}
+	b.EndHash()
+	return h, nil
+}
+
+// tap writes `p` to each active hash.
+func (b *binaryBase) tap(p []byte) {
+	for _, h := range b.hashes {
+		h.Write(p)
+	}
+}
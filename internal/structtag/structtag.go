@@ -0,0 +1,53 @@
+// Package structtag parses `bin` struct tags. It is shared by the reflection
+// in `bin.Reader.ReadStruct` and `bin.Writer.WriteStruct` and by the bingen
+// code generator, such that both accept exactly the same tags.
+package structtag
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Tag holds the parsed options of a `bin:"..."` struct tag, which are
+// documented in package bin and by bingen.
+type Tag struct {
+	Ignore   bool
+	Order    binary.ByteOrder // nil unless given
+	Skip     int64
+	LenField string
+}
+
+// Parse parses the value `s` of a `bin` struct tag.
+func Parse(s string) (t Tag, err error) {
+	if s == "" {
+		return
+	}
+	if s == "-" {
+		t.Ignore = true
+		return
+	}
+	for _, opt := range strings.Split(s, ",") {
+		opt = strings.TrimSpace(opt)
+		switch {
+		case opt == "big" || opt == "be":
+			t.Order = binary.BigEndian
+		case opt == "little" || opt == "le":
+			t.Order = binary.LittleEndian
+		case strings.HasPrefix(opt, "skip="):
+			t.Skip, err = strconv.ParseInt(opt[len("skip="):], 10, 64)
+			if err != nil || t.Skip < 0 {
+				return t, fmt.Errorf("invalid tag option %q", opt)
+			}
+		case strings.HasPrefix(opt, "len="):
+			t.LenField = opt[len("len="):]
+			if t.LenField == "" {
+				return t, fmt.Errorf("invalid tag option %q", opt)
+			}
+		default:
+			return t, fmt.Errorf("unknown tag option %q", opt)
+		}
+	}
+	return
+}
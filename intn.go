@@ -0,0 +1,170 @@
+package bin
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+/*
+===============================================================================
+    Reader
+===============================================================================
+*/
+
+// ReadUint24 reads an unsigned 24-bit integer into `dst` according to the
+// current byte order.
+func (b *Reader) ReadUint24(dst *uint32) error {
+	var v uint64
+	if err := b.readUintN("ReadUint24", &v, 3); err != nil {
+		return err
+	}
+	*dst = uint32(v)
+	return nil
+}
+
+// ReadInt24 reads a signed 24-bit integer into `dst` according to the current
+// byte order, extending its sign.
+func (b *Reader) ReadInt24(dst *int32) error {
+	var v uint64
+	if err := b.readUintN("ReadInt24", &v, 3); err != nil {
+		return err
+	}
+	*dst = int32(signExtend(v, 3))
+	return nil
+}
+
+// ReadUintN reads an unsigned integer of `n` bytes, between 1 and 8, into
+// `dst` according to the current byte order. This is useful for the 40-, 48-
+// and 56-bit integers of some formats.
+func (b *Reader) ReadUintN(dst *uint64, n int) error {
+	return b.readUintN("ReadUintN", dst, n)
+}
+
+// ReadIntN reads a signed integer of `n` bytes, between 1 and 8, into `dst`
+// according to the current byte order, extending its sign.
+func (b *Reader) ReadIntN(dst *int64, n int) error {
+	var v uint64
+	if err := b.readUintN("ReadIntN", &v, n); err != nil {
+		return err
+	}
+	*dst = signExtend(v, n)
+	return nil
+}
+
+// readUintN is the implementation of the `Read*24` and `Read*N` methods.
+func (b *Reader) readUintN(op string, dst *uint64, n int) error {
+	if b.source == nil {
+		return b.newError(op, ErrNilSource)
+	}
+	if b.bo == nil {
+		return b.newError(op, ErrNilByteOrder)
+	}
+	if n < 1 || n > 8 {
+		return b.newError(op, fmt.Errorf("%w: %d", errBadWidth, n))
+	}
+	if err := b.readFull(op, b._1kb[:n]); err != nil {
+		return err
+	}
+	var v uint64
+	if b.littleEndian() {
+		for i := n - 1; i >= 0; i-- {
+			v = v<<8 | uint64(b._1kb[i])
+		}
+	} else {
+		for _, c := range b._1kb[:n] {
+			v = v<<8 | uint64(c)
+		}
+	}
+	*dst = v
+	return nil
+}
+
+/*
+===============================================================================
+    Writer
+===============================================================================
+*/
+
+// WriteUint24 writes an unsigned 24-bit integer according to the current byte
+// order. An error wrapping `ErrOverflow` is returned if `src` exceeds 24 bits.
+func (b *Writer) WriteUint24(src uint32) error {
+	return b.writeUintN("WriteUint24", uint64(src), 3, false)
+}
+
+// WriteInt24 writes a signed 24-bit integer according to the current byte
+// order. An error wrapping `ErrOverflow` is returned if `src` is not within
+// the range of a signed 24-bit integer.
+func (b *Writer) WriteInt24(src int32) error {
+	return b.writeUintN("WriteInt24", uint64(src), 3, true)
+}
+
+// WriteUintN writes an unsigned integer of `n` bytes, between 1 and 8,
+// according to the current byte order. An error wrapping `ErrOverflow` is
+// returned if `src` does not fit.
+func (b *Writer) WriteUintN(src uint64, n int) error {
+	return b.writeUintN("WriteUintN", src, n, false)
+}
+
+// WriteIntN writes a signed integer of `n` bytes, between 1 and 8, according
+// to the current byte order. An error wrapping `ErrOverflow` is returned if
+// `src` does not fit.
+func (b *Writer) WriteIntN(src int64, n int) error {
+	return b.writeUintN("WriteIntN", uint64(src), n, true)
+}
+
+// writeUintN is the implementation of the `Write*24` and `Write*N` methods,
+// writing the low `n` bytes of `src`, which is two's complement if `signed`.
+func (b *Writer) writeUintN(op string, src uint64, n int, signed bool) error {
+	if b.dest == nil {
+		return b.newError(op, ErrNilSource)
+	}
+	if b.bo == nil {
+		return b.newError(op, ErrNilByteOrder)
+	}
+	if n < 1 || n > 8 {
+		return b.newError(op, fmt.Errorf("%w: %d", errBadWidth, n))
+	}
+	if signed && signExtend(src, n) != int64(src) {
+		return b.newError(op, fmt.Errorf("%w: %d does not fit in %d bytes", ErrOverflow, int64(src), n))
+	}
+	if !signed && n < 8 && src>>(8*n) != 0 {
+		return b.newError(op, fmt.Errorf("%w: %d does not fit in %d bytes", ErrOverflow, src, n))
+	}
+	if b.littleEndian() {
+		for i := 0; i < n; i++ {
+			b._1kb[i] = byte(src >> (8 * i))
+		}
+	} else {
+		for i := 0; i < n; i++ {
+			b._1kb[n-1-i] = byte(src >> (8 * i))
+		}
+	}
+	return b.writeFull(op, b._1kb[:n])
+}
+
+/*
+===============================================================================
+    binaryBase
+===============================================================================
+*/
+
+// littleEndian reports whether the current byte order places the least
+// significant byte first. Byte orders other than those of `encoding/binary`
+// are probed, using the end of the temporary buffer.
+func (b *binaryBase) littleEndian() bool {
+	switch b.bo {
+	case binary.LittleEndian:
+		return true
+	case binary.BigEndian:
+		return false
+	}
+	b.bo.PutUint16(b._1kb[len(b._1kb)-2:], 1)
+	return b._1kb[len(b._1kb)-2] == 1
+}
+
+// signExtend interprets the low `n` bytes of `v` as a two's complement
+// integer.
+func signExtend(v uint64, n int) int64 {
+	shift := 64 - 8*n
+	return int64(v<<shift) >> shift
+}
@@ -0,0 +1,99 @@
+package bin
+
+/*
+===============================================================================
+    Reader
+===============================================================================
+*/
+
+// Mark sets a mark at the current position, after which consumed bytes are
+// retained in the read-ahead buffer so that `Rewind` may return to it. This
+// permits speculative parsing of sources which cannot seek, such as pipes and
+// sockets:
+//
+//	br.Mark()
+//	if err := parseV2(&br); err != nil {
+//		br.Rewind()
+//		err = parseV1(&br)
+//	}
+//	br.Release()
+//
+// Setting a mark replaces any existing one. At most the retention limit (see
+// `SetRetentionLimit`) of bytes are retained: consuming more invalidates the
+// mark, as does seeking outside of the buffer (see `Seek`), after which
+// `Rewind` returns an error.
+func (b *Reader) Mark() {
+	b.mark, b.markPos = b.off, b.pos
+	b.marked, b.markLost = true, false
+}
+
+// Rewind returns the reader to the position of the mark set by `Mark`, such
+// that the bytes consumed since are read again. The mark remains set, so
+// `Rewind` may be called repeatedly.
+//
+// Bytes read again are also written again to any active hash (see
+// `BeginHash`).
+func (b *Reader) Rewind() error {
+	if !b.marked {
+		if b.markLost {
+			return b.newError("Rewind", errMarkLost)
+		}
+		return b.newError("Rewind", errNoMark)
+	}
+	b.off, b.pos = b.mark, b.markPos
+	return nil
+}
+
+// Release removes the mark set by `Mark`, allowing the bytes retained since
+// to be discarded.
+func (b *Reader) Release() {
+	b.marked, b.markLost = false, false
+	if b.off == len(b.buf) {
+		b.buf = b.buf[:0]
+		b.off = 0
+	}
+}
+
+// SetRetentionLimit sets the maximum number of bytes which may be retained
+// after `Mark`. The default, used if `n` is less than one, is
+// `DefaultRetentionLimit`.
+func (b *Reader) SetRetentionLimit(n int) {
+	if n < 0 {
+		n = 0
+	}
+	b.maxRetain = n
+}
+
+// retentionLimit returns the maximum number of bytes which may be retained,
+// falling back to `DefaultRetentionLimit` if none has been set.
+func (b *Reader) retentionLimit() int {
+	if b.maxRetain == 0 {
+		return DefaultRetentionLimit
+	}
+	return b.maxRetain
+}
+
+// retaining reports whether `n` further bytes are to be retained when
+// consumed, invalidating the mark if they would exceed the retention limit.
+func (b *Reader) retaining(n int) bool {
+	if !b.marked {
+		return false
+	}
+	if b.off-b.mark+n > b.retentionLimit() {
+		b.loseMark()
+		return false
+	}
+	return true
+}
+
+// loseMark invalidates the mark, if any, such that `Rewind` returns an error.
+func (b *Reader) loseMark() {
+	if !b.marked {
+		return
+	}
+	b.marked, b.markLost = false, true
+	if b.off == len(b.buf) {
+		b.buf = b.buf[:0]
+		b.off = 0
+	}
+}
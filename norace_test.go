@@ -0,0 +1,7 @@
+//go:build !race
+// +build !race
+
+package bin
+
+// raceEnabled reports whether the race detector is enabled. See race_test.go.
+const raceEnabled = false
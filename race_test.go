@@ -0,0 +1,8 @@
+//go:build race
+// +build race
+
+package bin
+
+// raceEnabled reports whether the race detector is enabled, under which
+// `sync.Pool` drops items at random and allocation counts are unreliable.
+const raceEnabled = true
@@ -0,0 +1,222 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"sync"
+)
+
+/*
+===============================================================================
+    Data Types
+===============================================================================
+*/
+
+// ReaderAt provides methods for reading various data types at absolute
+// offsets of an `io.ReaderAt`, as is required by formats built around offset
+// tables (such as TIFF or ZIP).
+//
+// Unlike `Reader`, no cursor or temporary buffers are shared between calls,
+// so a `ReaderAt` is safe for concurrent use provided its source is, and its
+// byte order is not changed concurrently.
+type ReaderAt struct {
+	source io.ReaderAt
+	bo     binary.ByteOrder
+}
+
+// scratchAt holds temporary buffers for the typed reads of `ReaderAt`, which
+// would otherwise escape through `io.ReaderAt` and allocate on every read.
+var scratchAt = sync.Pool{New: func() interface{} { return new([8]byte) }}
+
+/*
+===============================================================================
+    ReaderAt
+===============================================================================
+*/
+
+// ReadBytesAt attempts to read `len(dst)` bytes into `dst`, starting at
+// offset `off` of the source.
+//
+// If unable to completely read into `dst`, an `*Error` wrapping
+// `io.ErrUnexpectedEOF` (or `io.EOF`, if no bytes were read) will be returned.
+func (b *ReaderAt) ReadBytesAt(dst []byte, off int64) error {
+	if b.source == nil {
+		return &Error{Op: "ReadBytesAt", Offset: off, Err: ErrNilSource}
+	}
+	return b.readAt("ReadBytesAt", dst, off)
+}
+
+// ReadByteAt reads the byte at offset `off` into `dst`.
+func (b *ReaderAt) ReadByteAt(dst *byte, off int64) error {
+	if b.source == nil {
+		return &Error{Op: "ReadByteAt", Offset: off, Err: ErrNilSource}
+	}
+	tmp := scratchAt.Get().(*[8]byte)
+	defer scratchAt.Put(tmp)
+	if err := b.readAt("ReadByteAt", tmp[:1], off); err != nil {
+		return err
+	}
+	*dst = tmp[0]
+	return nil
+}
+
+// ReadUint16At reads an unsigned 16-bit integer at offset `off` into `dst`
+// according to the current byte order.
+func (b *ReaderAt) ReadUint16At(dst *uint16, off int64) error {
+	tmp := scratchAt.Get().(*[8]byte)
+	defer scratchAt.Put(tmp)
+	if err := b.readFixedAt("ReadUint16At", tmp[:2], off); err != nil {
+		return err
+	}
+	*dst = b.bo.Uint16(tmp[:2])
+	return nil
+}
+
+// ReadUint32At reads an unsigned 32-bit integer at offset `off` into `dst`
+// according to the current byte order.
+func (b *ReaderAt) ReadUint32At(dst *uint32, off int64) error {
+	tmp := scratchAt.Get().(*[8]byte)
+	defer scratchAt.Put(tmp)
+	if err := b.readFixedAt("ReadUint32At", tmp[:4], off); err != nil {
+		return err
+	}
+	*dst = b.bo.Uint32(tmp[:4])
+	return nil
+}
+
+// ReadUint64At reads an unsigned 64-bit integer at offset `off` into `dst`
+// according to the current byte order.
+func (b *ReaderAt) ReadUint64At(dst *uint64, off int64) error {
+	tmp := scratchAt.Get().(*[8]byte)
+	defer scratchAt.Put(tmp)
+	if err := b.readFixedAt("ReadUint64At", tmp[:8], off); err != nil {
+		return err
+	}
+	*dst = b.bo.Uint64(tmp[:8])
+	return nil
+}
+
+// ReadInt16At reads a signed 16-bit integer at offset `off` into `dst`
+// according to the current byte order.
+func (b *ReaderAt) ReadInt16At(dst *int16, off int64) error {
+	tmp := scratchAt.Get().(*[8]byte)
+	defer scratchAt.Put(tmp)
+	if err := b.readFixedAt("ReadInt16At", tmp[:2], off); err != nil {
+		return err
+	}
+	*dst = int16(b.bo.Uint16(tmp[:2]))
+	return nil
+}
+
+// ReadInt32At reads a signed 32-bit integer at offset `off` into `dst`
+// according to the current byte order.
+func (b *ReaderAt) ReadInt32At(dst *int32, off int64) error {
+	tmp := scratchAt.Get().(*[8]byte)
+	defer scratchAt.Put(tmp)
+	if err := b.readFixedAt("ReadInt32At", tmp[:4], off); err != nil {
+		return err
+	}
+	*dst = int32(b.bo.Uint32(tmp[:4]))
+	return nil
+}
+
+// ReadInt64At reads a signed 64-bit integer at offset `off` into `dst`
+// according to the current byte order.
+func (b *ReaderAt) ReadInt64At(dst *int64, off int64) error {
+	tmp := scratchAt.Get().(*[8]byte)
+	defer scratchAt.Put(tmp)
+	if err := b.readFixedAt("ReadInt64At", tmp[:8], off); err != nil {
+		return err
+	}
+	*dst = int64(b.bo.Uint64(tmp[:8]))
+	return nil
+}
+
+// ReadFloat32At reads a 32-bit IEEE 754 floating-point integer at offset
+// `off` into `dst` according to the current byte order.
+func (b *ReaderAt) ReadFloat32At(dst *float32, off int64) error {
+	tmp := scratchAt.Get().(*[8]byte)
+	defer scratchAt.Put(tmp)
+	if err := b.readFixedAt("ReadFloat32At", tmp[:4], off); err != nil {
+		return err
+	}
+	*dst = math.Float32frombits(b.bo.Uint32(tmp[:4]))
+	return nil
+}
+
+// ReadFloat64At reads a 64-bit IEEE 754 floating-point integer at offset
+// `off` into `dst` according to the current byte order.
+func (b *ReaderAt) ReadFloat64At(dst *float64, off int64) error {
+	tmp := scratchAt.Get().(*[8]byte)
+	defer scratchAt.Put(tmp)
+	if err := b.readFixedAt("ReadFloat64At", tmp[:8], off); err != nil {
+		return err
+	}
+	*dst = math.Float64frombits(b.bo.Uint64(tmp[:8]))
+	return nil
+}
+
+// SetByteOrder sets the current byte order to `bo`.
+//
+// This is not safe to call concurrently with any of the `ReadXYZAt` methods.
+func (b *ReaderAt) SetByteOrder(bo binary.ByteOrder) {
+	b.bo = bo
+}
+
+// GetByteOrder returns the current byte order.
+//
+// Note that this can be `nil` if the interface was not created via a
+// constructor method.
+func (b *ReaderAt) GetByteOrder() binary.ByteOrder {
+	return b.bo
+}
+
+// readFixedAt validates that the source and byte order are set prior to a
+// typed read, then fills `dst` from offset `off`.
+func (b *ReaderAt) readFixedAt(op string, dst []byte, off int64) error {
+	if b.source == nil {
+		return &Error{Op: op, Offset: off, Err: ErrNilSource}
+	}
+	if b.bo == nil {
+		return &Error{Op: op, Offset: off, Err: ErrNilByteOrder}
+	}
+	return b.readAt(op, dst, off)
+}
+
+// readAt fills `dst` from offset `off`, normalising short reads in the same
+// manner as `io.ReadFull`, and describing any failure as an `*Error` for the
+// operation `op`.
+func (b *ReaderAt) readAt(op string, dst []byte, off int64) error {
+	n, err := b.source.ReadAt(dst, off)
+	if n == len(dst) {
+		// `io.ReaderAt` may return `io.EOF` alongside a complete read
+		return nil
+	}
+	if err == nil || (err == io.EOF && n > 0) {
+		err = io.ErrUnexpectedEOF
+	}
+	return &Error{Op: op, Offset: off, Requested: len(dst), Got: n, Err: err}
+}
+
+// NewReaderAt creates a new `ReaderAt` encapsulating the given `source`,
+// and using the byte order `bo` to specify endianness.
+//
+// For futureproofing, it is suggested to use these constructors rather than
+// manually creating an instance (i.e. `br := ReaderAt{}`)
+func NewReaderAt(source io.ReaderAt, bo binary.ByteOrder) ReaderAt {
+	return ReaderAt{source: source, bo: bo}
+}
+
+// NewReaderAtBytes creates a new `ReaderAt` to read from the given `source`,
+// using the byte order `bo` to specify endianness.
+//
+// Since `source` is a slice of bytes, a `bytes.Reader` will wrap the slice to satisfy
+// the `io.ReaderAt` interface.
+//
+// For futureproofing, it is suggested to use these constructors rather than
+// manually creating an instance (i.e. `br := ReaderAt{}`)
+func NewReaderAtBytes(source []byte, bo binary.ByteOrder) ReaderAt {
+	return NewReaderAt(bytes.NewReader(source), bo)
+}
@@ -0,0 +1,194 @@
+package bin
+
+import (
+	"fmt"
+	"io"
+)
+
+/*
+===============================================================================
+    Data Types
+===============================================================================
+*/
+
+// Reservation is a region of a `Writer`'s output whose contents are written
+// after the fact, such as a length or offset field preceding a body whose
+// size is not yet known. See `Writer.Reserve`.
+type Reservation struct {
+	w      *Writer
+	offset int64 // writer position of the region
+	size   int
+	abs    int64 // offset within a seekable destination, or -1 if held
+	epoch  int   // the writer's `epoch` when reserved
+	done   bool
+}
+
+/*
+===============================================================================
+    Writer
+===============================================================================
+*/
+
+// Reserve writes a placeholder of `size` null-bytes, returning a
+// `Reservation` with which its contents may later be patched:
+//
+//	length, _ := bw.Reserve(4)
+//	start := bw.GetPosition()
+//	writeBody(&bw)
+//	length.PatchUint32(uint32(bw.GetPosition() - start))
+//
+// If the destination implements `io.WriteSeeker`, the placeholder is written
+// immediately and patches are made by seeking back to it. Otherwise, it and
+// all subsequent writes are held in memory until every reservation has been
+// patched, at which point they are written to the destination in order; note
+// that the held writes are then lost if a reservation is never patched.
+func (b *Writer) Reserve(size int) (*Reservation, error) {
+	if b.dest == nil {
+		return nil, b.newError("Reserve", ErrNilSource)
+	}
+	if size < 0 {
+		return nil, b.newError("Reserve", ErrNegativeLength)
+	}
+	r := &Reservation{w: b, offset: b.pos, size: size, abs: -1, epoch: b.epoch}
+	if ws, ok := b.dest.(io.WriteSeeker); ok && b.open == 0 && len(b.held) == 0 {
+		// an unseekable destination (such as a pipe) falls back to holding
+		if abs, err := ws.Seek(0, io.SeekCurrent); err == nil {
+			r.abs = abs + int64(len(b.buf))
+		}
+	}
+	if r.abs < 0 {
+		if b.open == 0 && len(b.held) == 0 {
+			b.heldBase = b.pos
+		}
+		b.open++
+	}
+	if err := b.zeroFill(int64(size)); err != nil {
+		return nil, &Error{Op: "Reserve", Offset: r.offset, Requested: size, Got: int(b.pos - r.offset), Err: err}
+	}
+	return r, nil
+}
+
+// release writes the held bytes to the destination, retaining any which
+// could not be written.
+func (b *Writer) release() error {
+	// the buffered bytes precede the held ones
+	if err := b.flush(); err != nil {
+		return err
+	}
+	n, err := b.dest.Write(b.held)
+	if err == nil && n < len(b.held) {
+		err = io.ErrShortWrite
+	}
+	b.held = b.held[:copy(b.held, b.held[n:])]
+	b.heldBase += int64(n)
+	return err
+}
+
+/*
+===============================================================================
+    Reservation
+===============================================================================
+*/
+
+// Offset returns the writer position of the reserved region.
+func (r *Reservation) Offset() int64 {
+	return r.offset
+}
+
+// Size returns the size of the reserved region in bytes.
+func (r *Reservation) Size() int {
+	return r.size
+}
+
+// PatchUint8 writes `src` to the reserved region, which must be 1 byte.
+func (r *Reservation) PatchUint8(src uint8) error {
+	return r.patch("PatchUint8", []byte{src})
+}
+
+// PatchUint16 writes `src` to the reserved region, which must be 2 bytes,
+// using the writer's current byte order.
+func (r *Reservation) PatchUint16(src uint16) error {
+	if r.w.bo == nil {
+		return &Error{Op: "PatchUint16", Offset: r.offset, Err: ErrNilByteOrder}
+	}
+	var tmp [2]byte
+	r.w.bo.PutUint16(tmp[:], src)
+	return r.patch("PatchUint16", tmp[:])
+}
+
+// PatchUint32 writes `src` to the reserved region, which must be 4 bytes,
+// using the writer's current byte order.
+func (r *Reservation) PatchUint32(src uint32) error {
+	if r.w.bo == nil {
+		return &Error{Op: "PatchUint32", Offset: r.offset, Err: ErrNilByteOrder}
+	}
+	var tmp [4]byte
+	r.w.bo.PutUint32(tmp[:], src)
+	return r.patch("PatchUint32", tmp[:])
+}
+
+// PatchUint64 writes `src` to the reserved region, which must be 8 bytes,
+// using the writer's current byte order.
+func (r *Reservation) PatchUint64(src uint64) error {
+	if r.w.bo == nil {
+		return &Error{Op: "PatchUint64", Offset: r.offset, Err: ErrNilByteOrder}
+	}
+	var tmp [8]byte
+	r.w.bo.PutUint64(tmp[:], src)
+	return r.patch("PatchUint64", tmp[:])
+}
+
+// PatchBytes writes `src` to the reserved region, which must be the same
+// size as `src`.
+func (r *Reservation) PatchBytes(src []byte) error {
+	return r.patch("PatchBytes", src)
+}
+
+// patch is the implementation of the `Patch*` methods. A reservation may
+// only be patched once.
+func (r *Reservation) patch(op string, src []byte) error {
+	b := r.w
+	if r.done || r.epoch != b.epoch {
+		return &Error{Op: op, Offset: r.offset, Err: errStaleReserve}
+	}
+	if len(src) != r.size {
+		return &Error{Op: op, Offset: r.offset, Err: fmt.Errorf("%w: %d bytes into %d", errPatchSize, len(src), r.size)}
+	}
+	if r.abs < 0 {
+		copy(b.held[r.offset-b.heldBase:], src)
+		r.done = true
+		b.open--
+		if b.open == 0 {
+			// the held writes are now complete
+			if err := b.release(); err != nil {
+				return &Error{Op: op, Offset: b.heldBase, Err: err}
+			}
+		}
+		return nil
+	}
+	ws := b.dest.(io.WriteSeeker)
+	// the region may still be buffered
+	if err := b.flush(); err != nil {
+		return &Error{Op: op, Offset: r.offset, Err: err}
+	}
+	cur, err := ws.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return &Error{Op: op, Offset: r.offset, Err: err}
+	}
+	if _, err = ws.Seek(r.abs, io.SeekStart); err != nil {
+		return &Error{Op: op, Offset: r.offset, Err: err}
+	}
+	n, err := ws.Write(src)
+	if err == nil && n < len(src) {
+		err = io.ErrShortWrite
+	}
+	// always return to the end of the output
+	if _, serr := ws.Seek(cur, io.SeekStart); err == nil {
+		err = serr
+	}
+	if err != nil {
+		return &Error{Op: op, Offset: r.offset, Requested: len(src), Got: n, Err: err}
+	}
+	r.done = true
+	return nil
+}
@@ -0,0 +1,310 @@
+package bin
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"reflect"
+	"unsafe"
+)
+
+/*
+===============================================================================
+    Data Types
+===============================================================================
+*/
+
+// hostOrder is the byte order of the host, in which slices of numbers are
+// already laid out in memory.
+var hostOrder = func() binary.ByteOrder {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 1 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}()
+
+// bytesOf returns the memory of the slice at `s`, whose elements are `size`
+// bytes each, as a slice of bytes.
+func bytesOf(s unsafe.Pointer, size int) []byte {
+	var p []byte
+	src := (*reflect.SliceHeader)(s)
+	dst := (*reflect.SliceHeader)(unsafe.Pointer(&p))
+	dst.Data = src.Data
+	dst.Len = src.Len * size
+	dst.Cap = src.Len * size
+	return p
+}
+
+/*
+===============================================================================
+    Reader
+===============================================================================
+*/
+
+// ReadUint16s reads `len(dst)` unsigned 16-bit integers into `dst` according
+// to the current byte order.
+//
+// Rather than reading each value in turn, the bytes are read directly into
+// the memory of `dst` with a single `ReadBytes`, and are then converted in
+// place if the byte order differs from that of the host. If an error is
+// returned, the contents of `dst` are unspecified.
+func (b *Reader) ReadUint16s(dst []uint16) error {
+	return b.readUint16s("ReadUint16s", dst)
+}
+
+// ReadUint32s reads `len(dst)` unsigned 32-bit integers into `dst` according
+// to the current byte order. See `ReadUint16s`.
+func (b *Reader) ReadUint32s(dst []uint32) error {
+	return b.readUint32s("ReadUint32s", dst)
+}
+
+// ReadUint64s reads `len(dst)` unsigned 64-bit integers into `dst` according
+// to the current byte order. See `ReadUint16s`.
+func (b *Reader) ReadUint64s(dst []uint64) error {
+	return b.readUint64s("ReadUint64s", dst)
+}
+
+// ReadFloat32s reads `len(dst)` IEEE-754 32-bit floating-point numbers into
+// `dst` according to the current byte order. See `ReadUint16s`.
+func (b *Reader) ReadFloat32s(dst []float32) error {
+	if len(dst) == 0 {
+		return b.readUint32s("ReadFloat32s", nil)
+	}
+	return b.readUint32s("ReadFloat32s", *(*[]uint32)(unsafe.Pointer(&dst)))
+}
+
+// ReadFloat64s reads `len(dst)` IEEE-754 64-bit floating-point numbers into
+// `dst` according to the current byte order. See `ReadUint16s`.
+func (b *Reader) ReadFloat64s(dst []float64) error {
+	if len(dst) == 0 {
+		return b.readUint64s("ReadFloat64s", nil)
+	}
+	return b.readUint64s("ReadFloat64s", *(*[]uint64)(unsafe.Pointer(&dst)))
+}
+
+// readUint16s is the implementation of `ReadUint16s`.
+func (b *Reader) readUint16s(op string, dst []uint16) error {
+	if b.source == nil {
+		return b.newError(op, ErrNilSource)
+	}
+	if b.bo == nil {
+		return b.newError(op, ErrNilByteOrder)
+	}
+	if len(dst) == 0 {
+		return nil
+	}
+	p := bytesOf(unsafe.Pointer(&dst), 2)
+	if err := b.readFull(op, p); err != nil {
+		return err
+	}
+	switch b.bo {
+	case hostOrder:
+	case binary.LittleEndian, binary.BigEndian:
+		for i, v := range dst {
+			dst[i] = bits.ReverseBytes16(v)
+		}
+	default:
+		// each value is decoded from its own bytes, so may be done in place
+		for i := range dst {
+			dst[i] = b.bo.Uint16(p[i*2:])
+		}
+	}
+	return nil
+}
+
+// readUint32s is the implementation of `ReadUint32s` and `ReadFloat32s`.
+func (b *Reader) readUint32s(op string, dst []uint32) error {
+	if b.source == nil {
+		return b.newError(op, ErrNilSource)
+	}
+	if b.bo == nil {
+		return b.newError(op, ErrNilByteOrder)
+	}
+	if len(dst) == 0 {
+		return nil
+	}
+	p := bytesOf(unsafe.Pointer(&dst), 4)
+	if err := b.readFull(op, p); err != nil {
+		return err
+	}
+	switch b.bo {
+	case hostOrder:
+	case binary.LittleEndian, binary.BigEndian:
+		for i, v := range dst {
+			dst[i] = bits.ReverseBytes32(v)
+		}
+	default:
+		for i := range dst {
+			dst[i] = b.bo.Uint32(p[i*4:])
+		}
+	}
+	return nil
+}
+
+// readUint64s is the implementation of `ReadUint64s` and `ReadFloat64s`.
+func (b *Reader) readUint64s(op string, dst []uint64) error {
+	if b.source == nil {
+		return b.newError(op, ErrNilSource)
+	}
+	if b.bo == nil {
+		return b.newError(op, ErrNilByteOrder)
+	}
+	if len(dst) == 0 {
+		return nil
+	}
+	p := bytesOf(unsafe.Pointer(&dst), 8)
+	if err := b.readFull(op, p); err != nil {
+		return err
+	}
+	switch b.bo {
+	case hostOrder:
+	case binary.LittleEndian, binary.BigEndian:
+		for i, v := range dst {
+			dst[i] = bits.ReverseBytes64(v)
+		}
+	default:
+		for i := range dst {
+			dst[i] = b.bo.Uint64(p[i*8:])
+		}
+	}
+	return nil
+}
+
+/*
+===============================================================================
+    Writer
+===============================================================================
+*/
+
+// WriteUint16s writes each unsigned 16-bit integer of `src` according to the
+// current byte order.
+//
+// If the byte order is that of the host, the memory of `src` is written
+// directly with a single `WriteBytes`; otherwise values are converted in
+// batches through a temporary buffer. `src` is never modified.
+func (b *Writer) WriteUint16s(src []uint16) error {
+	return b.writeUint16s("WriteUint16s", src)
+}
+
+// WriteUint32s writes each unsigned 32-bit integer of `src` according to the
+// current byte order. See `WriteUint16s`.
+func (b *Writer) WriteUint32s(src []uint32) error {
+	return b.writeUint32s("WriteUint32s", src)
+}
+
+// WriteUint64s writes each unsigned 64-bit integer of `src` according to the
+// current byte order. See `WriteUint16s`.
+func (b *Writer) WriteUint64s(src []uint64) error {
+	return b.writeUint64s("WriteUint64s", src)
+}
+
+// WriteFloat32s writes each IEEE-754 32-bit floating-point number of `src`
+// according to the current byte order. See `WriteUint16s`.
+func (b *Writer) WriteFloat32s(src []float32) error {
+	if len(src) == 0 {
+		return b.writeUint32s("WriteFloat32s", nil)
+	}
+	return b.writeUint32s("WriteFloat32s", *(*[]uint32)(unsafe.Pointer(&src)))
+}
+
+// WriteFloat64s writes each IEEE-754 64-bit floating-point number of `src`
+// according to the current byte order. See `WriteUint16s`.
+func (b *Writer) WriteFloat64s(src []float64) error {
+	if len(src) == 0 {
+		return b.writeUint64s("WriteFloat64s", nil)
+	}
+	return b.writeUint64s("WriteFloat64s", *(*[]uint64)(unsafe.Pointer(&src)))
+}
+
+// writeUint16s is the implementation of `WriteUint16s`.
+func (b *Writer) writeUint16s(op string, src []uint16) error {
+	if b.dest == nil {
+		return b.newError(op, ErrNilSource)
+	}
+	if b.bo == nil {
+		return b.newError(op, ErrNilByteOrder)
+	}
+	if len(src) == 0 {
+		return nil
+	}
+	if b.bo == hostOrder {
+		return b.writeFull(op, bytesOf(unsafe.Pointer(&src), 2))
+	}
+	start, size := b.pos, len(src)*2
+	for len(src) > 0 {
+		n := len(b._1kb) / 2
+		if len(src) < n {
+			n = len(src)
+		}
+		for i, v := range src[:n] {
+			b.bo.PutUint16(b._1kb[i*2:], v)
+		}
+		if err := b.write(b._1kb[:n*2]); err != nil {
+			return &Error{Op: op, Offset: start, Requested: size, Got: int(b.pos - start), Err: err}
+		}
+		src = src[n:]
+	}
+	return nil
+}
+
+// writeUint32s is the implementation of `WriteUint32s` and `WriteFloat32s`.
+func (b *Writer) writeUint32s(op string, src []uint32) error {
+	if b.dest == nil {
+		return b.newError(op, ErrNilSource)
+	}
+	if b.bo == nil {
+		return b.newError(op, ErrNilByteOrder)
+	}
+	if len(src) == 0 {
+		return nil
+	}
+	if b.bo == hostOrder {
+		return b.writeFull(op, bytesOf(unsafe.Pointer(&src), 4))
+	}
+	start, size := b.pos, len(src)*4
+	for len(src) > 0 {
+		n := len(b._1kb) / 4
+		if len(src) < n {
+			n = len(src)
+		}
+		for i, v := range src[:n] {
+			b.bo.PutUint32(b._1kb[i*4:], v)
+		}
+		if err := b.write(b._1kb[:n*4]); err != nil {
+			return &Error{Op: op, Offset: start, Requested: size, Got: int(b.pos - start), Err: err}
+		}
+		src = src[n:]
+	}
+	return nil
+}
+
+// writeUint64s is the implementation of `WriteUint64s` and `WriteFloat64s`.
+func (b *Writer) writeUint64s(op string, src []uint64) error {
+	if b.dest == nil {
+		return b.newError(op, ErrNilSource)
+	}
+	if b.bo == nil {
+		return b.newError(op, ErrNilByteOrder)
+	}
+	if len(src) == 0 {
+		return nil
+	}
+	if b.bo == hostOrder {
+		return b.writeFull(op, bytesOf(unsafe.Pointer(&src), 8))
+	}
+	start, size := b.pos, len(src)*8
+	for len(src) > 0 {
+		n := len(b._1kb) / 8
+		if len(src) < n {
+			n = len(src)
+		}
+		for i, v := range src[:n] {
+			b.bo.PutUint64(b._1kb[i*8:], v)
+		}
+		if err := b.write(b._1kb[:n*8]); err != nil {
+			return &Error{Op: op, Offset: start, Requested: size, Got: int(b.pos - start), Err: err}
+		}
+		src = src[n:]
+	}
+	return nil
+}
@@ -0,0 +1,411 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+/*
+===============================================================================
+    Data Types
+===============================================================================
+*/
+
+// PrefixWidth specifies how the length of a length-prefixed byte slice or
+// string is encoded.
+type PrefixWidth int
+
+const (
+	// PrefixUint8 is a single-byte length, allowing at most 255 bytes.
+	PrefixUint8 PrefixWidth = iota
+	// PrefixUint16 is an unsigned 16-bit length in the current byte order.
+	PrefixUint16
+	// PrefixUint32 is an unsigned 32-bit length in the current byte order.
+	PrefixUint32
+	// PrefixUvarint is an unsigned LEB128-encoded length (see `ReadUvarint`).
+	PrefixUvarint
+)
+
+// Trim specifies the padding removed from the end of a fixed-width string by
+// `Reader.ReadFixedString`. Values may be combined, such as
+// `TrimNulls | TrimSpaces`.
+type Trim int
+
+const (
+	// TrimNulls removes trailing null-bytes.
+	TrimNulls Trim = 1 << iota
+	// TrimSpaces removes trailing spaces.
+	TrimSpaces
+)
+
+// scanChunk is the most bytes requested from the source at once while
+// scanning for a terminator.
+const scanChunk = 256
+
+/*
+===============================================================================
+    Reader
+===============================================================================
+*/
+
+// ReadPrefixedBytes reads a byte slice preceded by a length of the given
+// `width` into `dst`, reusing the capacity of `*dst` where possible.
+//
+// As the length is read from the stream, `max` must be given to bound the
+// allocation it may cause: a length greater than `max` returns an error
+// wrapping `ErrTooLong` before any allocation is made. Lengths within `max`
+// are allocated as the bytes arrive, rather than up front, so that a
+// truncated stream fails without allocating for the whole length.
+//
+// `*dst` is only updated if the read succeeds, but when its capacity is
+// sufficient the bytes are read directly into its backing array, the contents
+// of which are then unspecified if an error is returned.
+func (b *Reader) ReadPrefixedBytes(dst *[]byte, width PrefixWidth, max int) error {
+	if b.source == nil {
+		return b.newError("ReadPrefixedBytes", ErrNilSource)
+	}
+	n, err := b.readPrefix("ReadPrefixedBytes", width, max)
+	if err != nil {
+		return err
+	}
+	buf, err := b.readAlloc("ReadPrefixedBytes", *dst, n)
+	if err != nil {
+		return err
+	}
+	*dst = buf
+	return nil
+}
+
+// ReadPrefixedString reads a string preceded by a length of the given `width`
+// into `dst`. As with `ReadPrefixedBytes`, a length greater than `max`
+// returns an error wrapping `ErrTooLong`.
+func (b *Reader) ReadPrefixedString(dst *string, width PrefixWidth, max int) error {
+	if b.source == nil {
+		return b.newError("ReadPrefixedString", ErrNilSource)
+	}
+	n, err := b.readPrefix("ReadPrefixedString", width, max)
+	if err != nil {
+		return err
+	}
+	buf, err := b.readAlloc("ReadPrefixedString", b._1kb[:0], n)
+	if err != nil {
+		return err
+	}
+	*dst = string(buf)
+	return nil
+}
+
+// readPrefix reads a length of the given `width`, validating it against
+// `max`, and describing any failure as an `*Error` for the operation `op`.
+func (b *Reader) readPrefix(op string, width PrefixWidth, max int) (int, error) {
+	if max < 0 {
+		return 0, b.newError(op, ErrNegativeLength)
+	}
+	start := b.pos
+	n := uint64(0)
+	switch width {
+	case PrefixUint8:
+		if err := b.readFull(op, b._1kb[:1]); err != nil {
+			return 0, err
+		}
+		n = uint64(b._1kb[0])
+	case PrefixUint16:
+		if b.bo == nil {
+			return 0, b.newError(op, ErrNilByteOrder)
+		}
+		if err := b.readFull(op, b._1kb[:2]); err != nil {
+			return 0, err
+		}
+		n = uint64(b.bo.Uint16(b._1kb[:2]))
+	case PrefixUint32:
+		if b.bo == nil {
+			return 0, b.newError(op, ErrNilByteOrder)
+		}
+		if err := b.readFull(op, b._1kb[:4]); err != nil {
+			return 0, err
+		}
+		n = uint64(b.bo.Uint32(b._1kb[:4]))
+	case PrefixUvarint:
+		if err := b.readUvarint(op, &n); err != nil {
+			return 0, err
+		}
+	default:
+		return 0, b.newError(op, fmt.Errorf("%w: %d", errBadPrefix, width))
+	}
+	if n > uint64(max) {
+		requested := maxInt
+		if n < uint64(maxInt) {
+			requested = int(n)
+		}
+		return 0, &Error{Op: op, Offset: start, Requested: requested, Err: ErrTooLong}
+	}
+	return int(n), nil
+}
+
+// ReadCString reads a null-terminated string of at most `max` bytes (not
+// including the terminator) into `dst`, consuming the terminator.
+//
+// Bytes are scanned in the read-ahead buffer, so the source is read in chunks
+// rather than a byte at a time; any bytes read beyond the terminator remain
+// buffered for subsequent reads. If no terminator is found within `max`
+// bytes, an error wrapping `ErrTooLong` is returned, and the reader is not
+// advanced unless the string exceeded the read-ahead limit (see
+// `SetReadAheadLimit`), in which case the bytes scanned are consumed.
+func (b *Reader) ReadCString(dst *string, max int) error {
+	if b.source == nil {
+		return b.newError("ReadCString", ErrNilSource)
+	}
+	if max < 0 {
+		return b.newError("ReadCString", ErrNegativeLength)
+	}
+	start := b.pos
+	var long []byte // bytes consumed from a string exceeding the buffer
+	scanned := 0
+	for {
+		buf := b.buf[b.off:]
+		// written so as not to overflow when `max` is the largest `int`
+		if len(buf)-1 > max-len(long) {
+			buf = buf[:max-len(long)+1]
+		}
+		if i := bytes.IndexByte(buf[scanned:], 0); i >= 0 {
+			if long != nil {
+				*dst = string(append(long, buf[:scanned+i]...))
+			} else {
+				*dst = string(buf[:scanned+i])
+			}
+			b.consume(scanned + i + 1)
+			return nil
+		}
+		scanned = len(buf)
+		if len(long)+scanned > max {
+			return &Error{Op: "ReadCString", Offset: start, Err: ErrTooLong}
+		}
+		if scanned >= b.readAheadLimit() {
+			// the buffer is full, so continue scanning from its start
+			long = append(long, buf...)
+			b.consume(scanned)
+			scanned = 0
+		}
+		n := scanChunk
+		if max-len(long)-scanned < n {
+			n = max - len(long) - scanned + 1
+		}
+		if limit := b.readAheadLimit() - scanned; limit < n {
+			n = limit
+		}
+		if err := b.peekMore(n); err != nil {
+			if err == io.EOF && len(long)+scanned > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return &Error{Op: "ReadCString", Offset: start, Err: b.bounded(err)}
+		}
+	}
+}
+
+// ReadFixedString reads a string occupying exactly `n` bytes into `dst`,
+// removing any trailing padding specified by `trim` (which may be zero, to
+// keep the string as-is).
+func (b *Reader) ReadFixedString(dst *string, n int, trim Trim) error {
+	if b.source == nil {
+		return b.newError("ReadFixedString", ErrNilSource)
+	}
+	if n < 0 {
+		return b.newError("ReadFixedString", ErrNegativeLength)
+	}
+	// `n` may have been read from the source, so is allocated as the bytes
+	// arrive rather than up front
+	buf, err := b.readAlloc("ReadFixedString", b._1kb[:0], n)
+	if err != nil {
+		return err
+	}
+	switch trim & (TrimNulls | TrimSpaces) {
+	case TrimNulls:
+		buf = bytes.TrimRight(buf, "\x00")
+	case TrimSpaces:
+		buf = bytes.TrimRight(buf, " ")
+	case TrimNulls | TrimSpaces:
+		buf = bytes.TrimRight(buf, "\x00 ")
+	}
+	*dst = string(buf)
+	return nil
+}
+
+// peekMore reads at most `n` further bytes from the source into the
+// read-ahead buffer. Unlike `Peek`, only a single successful call is made to
+// `source.Read`, so that scanning does not block waiting for bytes it may
+// not need.
+func (b *Reader) peekMore(n int) error {
+	b.makeRoom(b.buffered() + n)
+	for i := 0; i < 100; i++ {
+		m, err := b.source.Read(b.buf[len(b.buf) : len(b.buf)+n])
+		if m > 0 {
+			// any error will recur on the next read
+			b.buf = b.buf[:len(b.buf)+m]
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return io.ErrNoProgress
+}
+
+/*
+===============================================================================
+    Writer
+===============================================================================
+*/
+
+// WritePrefixedBytes writes `src` preceded by its length, encoded according
+// to `width`. An error wrapping `ErrTooLong` is returned if the length cannot
+// be represented by the prefix.
+func (b *Writer) WritePrefixedBytes(src []byte, width PrefixWidth) error {
+	if b.dest == nil {
+		return b.newError("WritePrefixedBytes", ErrNilSource)
+	}
+	if err := b.writePrefix("WritePrefixedBytes", width, len(src)); err != nil {
+		return err
+	}
+	return b.writeFull("WritePrefixedBytes", src)
+}
+
+// WritePrefixedString writes `src` preceded by its length, encoded according
+// to `width`. An error wrapping `ErrTooLong` is returned if the length cannot
+// be represented by the prefix.
+func (b *Writer) WritePrefixedString(src string, width PrefixWidth) error {
+	if b.dest == nil {
+		return b.newError("WritePrefixedString", ErrNilSource)
+	}
+	if err := b.writePrefix("WritePrefixedString", width, len(src)); err != nil {
+		return err
+	}
+	return b.writeString("WritePrefixedString", src)
+}
+
+// writePrefix writes the length `n` according to `width`, describing any
+// failure as an `*Error` for the operation `op`.
+func (b *Writer) writePrefix(op string, width PrefixWidth, n int) error {
+	limit := uint64(0)
+	switch width {
+	case PrefixUint8:
+		limit = math.MaxUint8
+	case PrefixUint16:
+		limit = math.MaxUint16
+	case PrefixUint32:
+		limit = math.MaxUint32
+	case PrefixUvarint:
+		limit = math.MaxUint64
+	default:
+		return b.newError(op, fmt.Errorf("%w: %d", errBadPrefix, width))
+	}
+	if uint64(n) > limit {
+		return &Error{Op: op, Offset: b.pos, Requested: n, Err: ErrTooLong}
+	}
+	switch width {
+	case PrefixUint8:
+		b._1kb[0] = byte(n)
+		return b.writeFull(op, b._1kb[:1])
+	case PrefixUint16:
+		if b.bo == nil {
+			return b.newError(op, ErrNilByteOrder)
+		}
+		b.bo.PutUint16(b._1kb[:2], uint16(n))
+		return b.writeFull(op, b._1kb[:2])
+	case PrefixUint32:
+		if b.bo == nil {
+			return b.newError(op, ErrNilByteOrder)
+		}
+		b.bo.PutUint32(b._1kb[:4], uint32(n))
+		return b.writeFull(op, b._1kb[:4])
+	}
+	b.i = binary.PutUvarint(b._1kb[:binary.MaxVarintLen64], uint64(n))
+	if b.i > b.getMaxVarintLen() {
+		return b.newError(op, fmt.Errorf("%w: encoding exceeds %d bytes", ErrTooLong, b.getMaxVarintLen()))
+	}
+	return b.writeFull(op, b._1kb[:b.i])
+}
+
+// writeString writes all bytes of `src` via the temporary buffer, so as not
+// to allocate a copy, describing any failure as an `*Error` for the
+// operation `op`.
+func (b *Writer) writeString(op string, src string) error {
+	start := b.pos
+	n := len(src)
+	for len(src) > 0 {
+		b.i = copy(b._1kb[:], src)
+		if err := b.write(b._1kb[:b.i]); err != nil {
+			return &Error{Op: op, Offset: start, Requested: n, Got: int(b.pos - start), Err: err}
+		}
+		src = src[b.i:]
+	}
+	return nil
+}
+
+// WriteCString writes `src` followed by a null terminator. An error wrapping
+// `ErrInvalidEncoding` is returned if `src` itself contains a null-byte, as
+// it could not be read back.
+func (b *Writer) WriteCString(src string) error {
+	if b.dest == nil {
+		return b.newError("WriteCString", ErrNilSource)
+	}
+	if i := strings.IndexByte(src, 0); i >= 0 {
+		return b.newError("WriteCString", fmt.Errorf("%w: null-byte at index %d", ErrInvalidEncoding, i))
+	}
+	if err := b.writeString("WriteCString", src); err != nil {
+		return err
+	}
+	b._1kb[0] = 0
+	return b.writeFull("WriteCString", b._1kb[:1])
+}
+
+// WriteFixedString writes `src` as a field of exactly `n` bytes, padding it
+// with `pad` (typically a null-byte or space). An error wrapping
+// `ErrTooLong` is returned if `src` is longer than `n` bytes.
+func (b *Writer) WriteFixedString(src string, n int, pad byte) error {
+	if b.dest == nil {
+		return b.newError("WriteFixedString", ErrNilSource)
+	}
+	if n < 0 {
+		return b.newError("WriteFixedString", ErrNegativeLength)
+	}
+	if len(src) > n {
+		return &Error{Op: "WriteFixedString", Offset: b.pos, Requested: len(src), Err: ErrTooLong}
+	}
+	if err := b.writeString("WriteFixedString", src); err != nil {
+		return err
+	}
+	start := b.pos
+	if err := b.pad(int64(n-len(src)), pad); err != nil {
+		return &Error{Op: "WriteFixedString", Offset: start, Requested: n - len(src), Got: int(b.pos - start), Err: err}
+	}
+	return nil
+}
+
+// pad writes `n` copies of the byte `c`, returning errors from the
+// destination as-is.
+func (b *Writer) pad(n int64, c byte) error {
+	if c == 0 {
+		return b.zeroFill(n)
+	}
+	b.i = len(b._1kb)
+	if n < int64(b.i) {
+		b.i = int(n)
+	}
+	for i := range b._1kb[:b.i] {
+		b._1kb[i] = c
+	}
+	for n > 0 {
+		if n < int64(b.i) {
+			b.i = int(n)
+		}
+		if err := b.write(b._1kb[:b.i]); err != nil {
+			return err
+		}
+		n -= int64(b.i)
+	}
+	return nil
+}
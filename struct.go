@@ -0,0 +1,556 @@
+package bin
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+
+	"github.com/b71729/bin/internal/structtag"
+)
+
+/*
+===============================================================================
+    Data Types
+===============================================================================
+*/
+
+// structTag holds the parsed options of a `bin:"..."` struct tag.
+//
+// Options are separated by commas:
+//
+//	"-"         the field is ignored
+//	big, be     the field is big endian, regardless of the current byte order
+//	little, le  the field is little endian, regardless of the current byte order
+//	skip=N      N bytes of padding precede the field
+//	len=Field   the length of a slice or string is held in the sibling
+//	            integer field `Field`, which must precede it
+type structTag struct {
+	ignore   bool
+	bo       binary.ByteOrder
+	skip     int64
+	lenField string
+}
+
+// parseStructTag parses the `bin` tag of `f`.
+func parseStructTag(f reflect.StructField) (structTag, error) {
+	t, err := structtag.Parse(f.Tag.Get("bin"))
+	if err != nil {
+		return structTag{}, fmt.Errorf("field %s: %v", f.Name, err)
+	}
+	return structTag{ignore: t.Ignore, bo: t.Order, skip: t.Skip, lenField: t.LenField}, nil
+}
+
+// structLenField returns the sibling length field named `name` of the field
+// at index `i` of the struct `v`.
+func structLenField(v reflect.Value, i int, name string) (reflect.Value, error) {
+	f, ok := v.Type().FieldByName(name)
+	if !ok || len(f.Index) != 1 {
+		return reflect.Value{}, fmt.Errorf("field %s: length field %s does not exist", v.Type().Field(i).Name, name)
+	}
+	if f.Index[0] >= i {
+		return reflect.Value{}, fmt.Errorf("field %s: length field %s must precede it", v.Type().Field(i).Name, name)
+	}
+	switch f.Type.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Field(f.Index[0]), nil
+	}
+	return reflect.Value{}, fmt.Errorf("field %s: length field %s is not an integer", v.Type().Field(i).Name, name)
+}
+
+/*
+===============================================================================
+    Reader
+===============================================================================
+*/
+
+// ReadStruct decodes the exported fields of the struct pointed to by `ptr`,
+// in declaration order, according to the current byte order.
+//
+// Supported field kinds are the fixed-size integers, floats, bools (one byte,
+// non-zero being true), arrays and nested structs of these. Slices and
+// strings are supported when their length is declared by a sibling field via
+// a `len=` struct tag. Blank (`_`) fields are treated as padding and skipped.
+// See the `bin` struct tag options documented on `structTag`, for example:
+//
+//	type Header struct {
+//		Magic   [4]byte
+//		Version uint16 `bin:"big"`
+//		Count   uint32 `bin:"skip=2"`
+//		Entries []uint32 `bin:"len=Count"`
+//	}
+//
+// As it is reflection-based, this is considerably slower than calling the
+// `ReadXYZ` methods directly.
+func (b *Reader) ReadStruct(ptr interface{}) error {
+	if b.source == nil {
+		return b.newError("ReadStruct", ErrNilSource)
+	}
+	if b.bo == nil {
+		return b.newError("ReadStruct", ErrNilByteOrder)
+	}
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return b.newError("ReadStruct", fmt.Errorf("expected a non-nil pointer to a struct, got %T", ptr))
+	}
+	start := b.pos
+	bo := b.bo
+	err := b.readStruct(v.Elem())
+	b.bo = bo
+	if err != nil {
+		return wrapError("ReadStruct", start, err)
+	}
+	return nil
+}
+
+// readStruct decodes each field of the struct `v`.
+func (b *Reader) readStruct(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, err := parseStructTag(f)
+		if err != nil {
+			return err
+		}
+		if tag.ignore {
+			continue
+		}
+		if err = b.Discard(tag.skip); err != nil {
+			return err
+		}
+		if f.Name == "_" {
+			// padding
+			size := fixedSize(f.Type)
+			if size < 0 {
+				return fmt.Errorf("field %d: blank field of type %s is not fixed-size", i, f.Type)
+			}
+			if err = b.Discard(int64(size)); err != nil {
+				return err
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+		bo := b.bo
+		if tag.bo != nil {
+			b.bo = tag.bo
+		}
+		if tag.lenField != "" {
+			err = b.readSized(v, i, tag.lenField)
+		} else {
+			err = b.readValue(v.Field(i))
+		}
+		b.bo = bo
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readSized decodes the slice or string field at index `i` of the struct `v`,
+// whose length is held by the sibling field `lenField`.
+func (b *Reader) readSized(v reflect.Value, i int, lenField string) error {
+	lv, err := structLenField(v, i, lenField)
+	if err != nil {
+		return err
+	}
+	n := 0
+	if lv.Kind() >= reflect.Uint && lv.Kind() <= reflect.Uint64 {
+		if lv.Uint() > uint64(maxInt) {
+			return fmt.Errorf("field %s: length %d: %w", v.Type().Field(i).Name, lv.Uint(), ErrTooLong)
+		}
+		n = int(lv.Uint())
+	} else {
+		if lv.Int() < 0 || lv.Int() > int64(maxInt) {
+			if lv.Int() < 0 {
+				return fmt.Errorf("field %s: length %d: %w", v.Type().Field(i).Name, lv.Int(), ErrNegativeLength)
+			}
+			return fmt.Errorf("field %s: length %d: %w", v.Type().Field(i).Name, lv.Int(), ErrTooLong)
+		}
+		n = int(lv.Int())
+	}
+	// the length has been read from the source, and so may be hostile: rather
+	// than allocating it up front, memory is allocated as values are read
+	fv := v.Field(i)
+	switch fv.Kind() {
+	case reflect.String:
+		tmp, err := b.readAlloc("ReadBytes", nil, n)
+		if err != nil {
+			return err
+		}
+		fv.SetString(string(tmp))
+		return nil
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() == reflect.Uint8 {
+			tmp, err := b.readAlloc("ReadBytes", fv.Bytes(), n)
+			if err != nil {
+				return err
+			}
+			fv.SetBytes(tmp)
+			return nil
+		}
+		s := fv
+		if s.Cap() >= n {
+			s.SetLen(n)
+		} else {
+			s = reflect.MakeSlice(fv.Type(), 0, 0)
+		}
+		for j := 0; j < n; j++ {
+			if j == s.Len() {
+				s = growSlice(s, n)
+			}
+			if err = b.readValue(s.Index(j)); err != nil {
+				return err
+			}
+		}
+		fv.Set(s)
+		return nil
+	}
+	return fmt.Errorf("field %s: len tag is not supported for kind %s", v.Type().Field(i).Name, fv.Kind())
+}
+
+// readValue decodes a fixed-size value into `v`.
+func (b *Reader) readValue(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Bool:
+		if err := b.ReadBytes(b._1kb[:1]); err != nil {
+			return err
+		}
+		v.SetBool(b._1kb[0] != 0)
+	case reflect.Uint8:
+		if err := b.ReadBytes(b._1kb[:1]); err != nil {
+			return err
+		}
+		v.SetUint(uint64(b._1kb[0]))
+	case reflect.Int8:
+		if err := b.ReadBytes(b._1kb[:1]); err != nil {
+			return err
+		}
+		v.SetInt(int64(int8(b._1kb[0])))
+	case reflect.Uint16, reflect.Int16:
+		ui16 := uint16(0)
+		if err := b.ReadUint16(&ui16); err != nil {
+			return err
+		}
+		if v.Kind() == reflect.Int16 {
+			v.SetInt(int64(int16(ui16)))
+		} else {
+			v.SetUint(uint64(ui16))
+		}
+	case reflect.Uint32, reflect.Int32:
+		ui32 := uint32(0)
+		if err := b.ReadUint32(&ui32); err != nil {
+			return err
+		}
+		if v.Kind() == reflect.Int32 {
+			v.SetInt(int64(int32(ui32)))
+		} else {
+			v.SetUint(uint64(ui32))
+		}
+	case reflect.Uint64, reflect.Int64:
+		ui64 := uint64(0)
+		if err := b.ReadUint64(&ui64); err != nil {
+			return err
+		}
+		if v.Kind() == reflect.Int64 {
+			v.SetInt(int64(ui64))
+		} else {
+			v.SetUint(ui64)
+		}
+	case reflect.Float32:
+		f32 := float32(0)
+		if err := b.ReadFloat32(&f32); err != nil {
+			return err
+		}
+		v.SetFloat(float64(f32))
+	case reflect.Float64:
+		f64 := float64(0)
+		if err := b.ReadFloat64(&f64); err != nil {
+			return err
+		}
+		v.SetFloat(f64)
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return b.ReadBytes(v.Slice(0, v.Len()).Bytes())
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := b.readValue(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		return b.readStruct(v)
+	default:
+		return errUnsupportedKind(v)
+	}
+	return nil
+}
+
+/*
+===============================================================================
+    Writer
+===============================================================================
+*/
+
+// WriteStruct encodes the exported fields of the struct `v` (or the struct
+// pointed to by `v`), in declaration order, according to the current byte
+// order. It is the counterpart to `Reader.ReadStruct`, and accepts the same
+// field kinds and `bin` struct tags. Blank (`_`) fields and `skip=` padding
+// are written as null-bytes.
+//
+// Length fields referenced by a `len=` tag are filled in automatically from
+// the length of the slice or string: if the length field is zero it is
+// replaced, otherwise it must match. An error is also returned if the length
+// does not fit within the length field.
+func (b *Writer) WriteStruct(v interface{}) error {
+	if b.dest == nil {
+		return b.newError("WriteStruct", ErrNilSource)
+	}
+	if b.bo == nil {
+		return b.newError("WriteStruct", ErrNilByteOrder)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return b.newError("WriteStruct", fmt.Errorf("expected a struct or non-nil pointer to a struct, got %T", v))
+	}
+	if !rv.CanAddr() {
+		// byte arrays are written via a slice of their contents
+		tmp := reflect.New(rv.Type()).Elem()
+		tmp.Set(rv)
+		rv = tmp
+	}
+	start := b.pos
+	bo := b.bo
+	err := b.writeStruct(rv)
+	b.bo = bo
+	if err != nil {
+		return wrapError("WriteStruct", start, err)
+	}
+	return nil
+}
+
+// structLen records the length implied for a length field by a `len=` tag.
+type structLen struct {
+	field int
+	n     int
+}
+
+// writeStruct encodes each field of the struct `v`.
+func (b *Writer) writeStruct(v reflect.Value) error {
+	t := v.Type()
+	// determine the lengths implied for any length fields
+	var lens []structLen
+	for i := 0; i < t.NumField(); i++ {
+		tag, err := parseStructTag(t.Field(i))
+		if err != nil {
+			return err
+		}
+		if tag.ignore || tag.lenField == "" {
+			continue
+		}
+		lv, err := structLenField(v, i, tag.lenField)
+		if err != nil {
+			return err
+		}
+		fv := v.Field(i)
+		if fv.Kind() != reflect.Slice && fv.Kind() != reflect.String {
+			return fmt.Errorf("field %s: len tag is not supported for kind %s", t.Field(i).Name, fv.Kind())
+		}
+		if err = checkStructLen(lv, fv.Len()); err != nil {
+			return fmt.Errorf("field %s: %v", t.Field(i).Name, err)
+		}
+		field, _ := t.FieldByName(tag.lenField)
+		for _, l := range lens {
+			if l.field == field.Index[0] && l.n != fv.Len() {
+				return fmt.Errorf("field %s: length %d conflicts with %d for length field %s", t.Field(i).Name, fv.Len(), l.n, tag.lenField)
+			}
+		}
+		lens = append(lens, structLen{field: field.Index[0], n: fv.Len()})
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, _ := parseStructTag(f)
+		if tag.ignore {
+			continue
+		}
+		if err := b.ZeroFill(tag.skip); err != nil {
+			return err
+		}
+		if f.Name == "_" {
+			// padding
+			size := fixedSize(f.Type)
+			if size < 0 {
+				return fmt.Errorf("field %d: blank field of type %s is not fixed-size", i, f.Type)
+			}
+			if err := b.ZeroFill(int64(size)); err != nil {
+				return err
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+		bo := b.bo
+		if tag.bo != nil {
+			b.bo = tag.bo
+		}
+		fv := v.Field(i)
+		for _, l := range lens {
+			if l.field == i {
+				// substitute the implied length for a zero-valued length field
+				fv = reflect.New(f.Type).Elem()
+				if f.Type.Kind() >= reflect.Uint && f.Type.Kind() <= reflect.Uint64 {
+					fv.SetUint(uint64(l.n))
+				} else {
+					fv.SetInt(int64(l.n))
+				}
+				break
+			}
+		}
+		var err error
+		if tag.lenField != "" {
+			err = b.writeSized(fv)
+		} else {
+			err = b.writeValue(fv)
+		}
+		b.bo = bo
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkStructLen validates that the length field `lv` is either zero (to be
+// filled in automatically) or equal to `n`, and that `n` fits within it.
+func checkStructLen(lv reflect.Value, n int) error {
+	bits := uint(lv.Type().Bits())
+	if lv.Kind() >= reflect.Uint && lv.Kind() <= reflect.Uint64 {
+		if bits < 64 && uint64(n) >= 1<<bits {
+			return fmt.Errorf("length %d overflows %s", n, lv.Type())
+		}
+		if lv.Uint() != 0 && lv.Uint() != uint64(n) {
+			return fmt.Errorf("length field is %d, but length is %d", lv.Uint(), n)
+		}
+		return nil
+	}
+	if bits < 64 && int64(n) >= 1<<(bits-1) {
+		return fmt.Errorf("length %d overflows %s", n, lv.Type())
+	}
+	if lv.Int() != 0 && lv.Int() != int64(n) {
+		return fmt.Errorf("length field is %d, but length is %d", lv.Int(), n)
+	}
+	return nil
+}
+
+// writeSized encodes the slice or string `v`, whose length is recorded in a
+// sibling field.
+func (b *Writer) writeSized(v reflect.Value) error {
+	if v.Kind() == reflect.String {
+		return b.WriteBytes([]byte(v.String()))
+	}
+	if v.Type().Elem().Kind() == reflect.Uint8 {
+		return b.WriteBytes(v.Bytes())
+	}
+	for i := 0; i < v.Len(); i++ {
+		if err := b.writeValue(v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeValue encodes the fixed-size value `v`.
+func (b *Writer) writeValue(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return b.WriteByte(1)
+		}
+		return b.WriteByte(0)
+	case reflect.Uint8:
+		return b.WriteByte(byte(v.Uint()))
+	case reflect.Int8:
+		return b.WriteByte(byte(v.Int()))
+	case reflect.Uint16:
+		return b.WriteUint16(uint16(v.Uint()))
+	case reflect.Int16:
+		return b.WriteUint16(uint16(v.Int()))
+	case reflect.Uint32:
+		return b.WriteUint32(uint32(v.Uint()))
+	case reflect.Int32:
+		return b.WriteUint32(uint32(v.Int()))
+	case reflect.Uint64:
+		return b.WriteUint64(v.Uint())
+	case reflect.Int64:
+		return b.WriteUint64(uint64(v.Int()))
+	case reflect.Float32:
+		return b.WriteFloat32(float32(v.Float()))
+	case reflect.Float64:
+		return b.WriteFloat64(v.Float())
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return b.WriteBytes(v.Slice(0, v.Len()).Bytes())
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := b.writeValue(v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		return b.writeStruct(v)
+	}
+	return errUnsupportedKind(v)
+}
+
+// fixedSize returns the encoded size of the fixed-size type `t`, or -1 if `t`
+// is not fixed-size.
+func fixedSize(t reflect.Type) int {
+	if t.Kind() == reflect.Slice {
+		// `binary.Size` would report the size of the slice's contents
+		return -1
+	}
+	return binary.Size(reflect.Zero(t).Interface())
+}
+
+// errUnsupportedKind describes why the kind of `v` cannot be (de)serialised.
+func errUnsupportedKind(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Slice, reflect.String:
+		return fmt.Errorf("%s requires a len tag", v.Type())
+	case reflect.Int, reflect.Uint, reflect.Uintptr:
+		return fmt.Errorf("%s is not fixed-size", v.Type())
+	}
+	return fmt.Errorf("unsupported type %s", v.Type())
+}
+
+// growSlice returns a copy of the slice `s`, grown towards a length of `n`
+// in the manner of `Reader.readAlloc`.
+func growSlice(s reflect.Value, n int) reflect.Value {
+	elemSize := int(s.Type().Elem().Size())
+	if elemSize == 0 {
+		elemSize = 1
+	}
+	size := 2 * s.Len()
+	if size < allocChunk/elemSize {
+		size = allocChunk / elemSize
+	}
+	if size > n {
+		size = n
+	}
+	grown := reflect.MakeSlice(s.Type(), size, size)
+	reflect.Copy(grown, s)
+	return grown
+}
+
+// maxInt is the largest value of an `int`.
+const maxInt = int(^uint(0) >> 1)
@@ -0,0 +1,249 @@
+package bin
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+/*
+===============================================================================
+    Reader
+===============================================================================
+*/
+
+// ReadUTF16 reads a UTF-16 string of `n` 16-bit code units into `dst`,
+// according to the byte order `bo`, or the current byte order if `bo` is nil.
+//
+// A leading byte order mark (U+FEFF), if present, overrides `bo` and is not
+// included in `dst`. Surrogate pairs are combined, and unpaired surrogates
+// are replaced with U+FFFD. An error wrapping `ErrTooLong` is returned if `n`
+// code units would not fit in an `int` number of bytes.
+func (b *Reader) ReadUTF16(dst *string, n int, bo binary.ByteOrder) error {
+	if b.source == nil {
+		return b.newError("ReadUTF16", ErrNilSource)
+	}
+	if bo == nil {
+		bo = b.bo
+	}
+	if bo == nil {
+		return b.newError("ReadUTF16", ErrNilByteOrder)
+	}
+	if n < 0 {
+		return b.newError("ReadUTF16", ErrNegativeLength)
+	}
+	if n > maxInt/2 {
+		return &Error{Op: "ReadUTF16", Offset: b.pos, Requested: n, Err: ErrTooLong}
+	}
+	buf, err := b.readAlloc("ReadUTF16", b._1kb[:0], n*2)
+	if err != nil {
+		return err
+	}
+	if len(buf) >= 2 {
+		switch {
+		case buf[0] == 0xFE && buf[1] == 0xFF:
+			bo, buf = binary.BigEndian, buf[2:]
+		case buf[0] == 0xFF && buf[1] == 0xFE:
+			bo, buf = binary.LittleEndian, buf[2:]
+		}
+	}
+	sb := strings.Builder{}
+	sb.Grow(len(buf) / 2)
+	for i := 0; i < len(buf); i += 2 {
+		r := rune(bo.Uint16(buf[i:]))
+		if utf16.IsSurrogate(r) {
+			r2 := utf8.RuneError
+			if i+4 <= len(buf) {
+				r2 = rune(bo.Uint16(buf[i+2:]))
+			}
+			if r = utf16.DecodeRune(r, r2); r != utf8.RuneError {
+				i += 2
+			}
+		}
+		sb.WriteRune(r)
+	}
+	*dst = sb.String()
+	return nil
+}
+
+// ReadLatin1 reads an ISO-8859-1 (Latin-1) string of `n` bytes into `dst`,
+// converting it to UTF-8.
+func (b *Reader) ReadLatin1(dst *string, n int) error {
+	if b.source == nil {
+		return b.newError("ReadLatin1", ErrNilSource)
+	}
+	if n < 0 {
+		return b.newError("ReadLatin1", ErrNegativeLength)
+	}
+	buf, err := b.readAlloc("ReadLatin1", b._1kb[:0], n)
+	if err != nil {
+		return err
+	}
+	i := asciiPrefix(buf)
+	if i == len(buf) {
+		*dst = string(buf)
+		return nil
+	}
+	sb := strings.Builder{}
+	sb.Grow(len(buf) + len(buf) - i)
+	sb.Write(buf[:i])
+	for _, c := range buf[i:] {
+		sb.WriteRune(rune(c))
+	}
+	*dst = sb.String()
+	return nil
+}
+
+// ReadASCII reads a strictly 7-bit ASCII string of `n` bytes into `dst`.
+//
+// If any byte is outside of the ASCII range, an error wrapping
+// `ErrInvalidEncoding` is returned whose `Offset` is that of the first such
+// byte, and `dst` is not modified. All `n` bytes are consumed regardless.
+func (b *Reader) ReadASCII(dst *string, n int) error {
+	if b.source == nil {
+		return b.newError("ReadASCII", ErrNilSource)
+	}
+	if n < 0 {
+		return b.newError("ReadASCII", ErrNegativeLength)
+	}
+	start := b.pos
+	buf, err := b.readAlloc("ReadASCII", b._1kb[:0], n)
+	if err != nil {
+		return err
+	}
+	if i := asciiPrefix(buf); i < len(buf) {
+		return &Error{Op: "ReadASCII", Offset: start + int64(i), Err: fmt.Errorf("%w: byte %#02x is not ASCII", ErrInvalidEncoding, buf[i])}
+	}
+	*dst = string(buf)
+	return nil
+}
+
+/*
+===============================================================================
+    Writer
+===============================================================================
+*/
+
+// WriteUTF16 writes `src` encoded as UTF-16, according to the byte order
+// `bo`, or the current byte order if `bo` is nil. If `bom` is set, a byte
+// order mark (U+FEFF) is written first.
+//
+// Characters outside of the Basic Multilingual Plane are written as
+// surrogate pairs, and invalid UTF-8 is written as U+FFFD.
+func (b *Writer) WriteUTF16(src string, bo binary.ByteOrder, bom bool) error {
+	if b.dest == nil {
+		return b.newError("WriteUTF16", ErrNilSource)
+	}
+	if bo == nil {
+		bo = b.bo
+	}
+	if bo == nil {
+		return b.newError("WriteUTF16", ErrNilByteOrder)
+	}
+	start := b.pos
+	b.i = 0
+	if bom {
+		bo.PutUint16(b._1kb[:2], 0xFEFF)
+		b.i = 2
+	}
+	for _, r := range src {
+		if b.i > len(b._1kb)-4 {
+			if err := b.write(b._1kb[:b.i]); err != nil {
+				return &Error{Op: "WriteUTF16", Offset: start, Got: int(b.pos - start), Err: err}
+			}
+			b.i = 0
+		}
+		if r >= 0x10000 {
+			r1, r2 := utf16.EncodeRune(r)
+			bo.PutUint16(b._1kb[b.i:], uint16(r1))
+			bo.PutUint16(b._1kb[b.i+2:], uint16(r2))
+			b.i += 4
+			continue
+		}
+		// invalid UTF-8 is already decoded as U+FFFD
+		bo.PutUint16(b._1kb[b.i:], uint16(r))
+		b.i += 2
+	}
+	if err := b.write(b._1kb[:b.i]); err != nil {
+		return &Error{Op: "WriteUTF16", Offset: start, Got: int(b.pos - start), Err: err}
+	}
+	return nil
+}
+
+// WriteLatin1 writes `src` encoded as ISO-8859-1 (Latin-1), one byte per
+// character. An error wrapping `ErrInvalidEncoding` is returned, and nothing
+// is written, if `src` contains a character beyond U+00FF or invalid UTF-8.
+func (b *Writer) WriteLatin1(src string) error {
+	if b.dest == nil {
+		return b.newError("WriteLatin1", ErrNilSource)
+	}
+	i := asciiPrefixString(src)
+	if i == len(src) {
+		return b.writeString("WriteLatin1", src)
+	}
+	for j := i; j < len(src); {
+		r, size := utf8.DecodeRuneInString(src[j:])
+		if r == utf8.RuneError && size == 1 {
+			return b.newError("WriteLatin1", fmt.Errorf("%w: invalid UTF-8 at index %d", ErrInvalidEncoding, j))
+		}
+		if r > 0xFF {
+			return b.newError("WriteLatin1", fmt.Errorf("%w: %U at index %d cannot be encoded as Latin-1", ErrInvalidEncoding, r, j))
+		}
+		j += size
+	}
+	start := b.pos
+	if err := b.writeString("WriteLatin1", src[:i]); err != nil {
+		return err
+	}
+	b.i = 0
+	for _, r := range src[i:] {
+		if b.i == len(b._1kb) {
+			if err := b.write(b._1kb[:]); err != nil {
+				return &Error{Op: "WriteLatin1", Offset: start, Got: int(b.pos - start), Err: err}
+			}
+			b.i = 0
+		}
+		b._1kb[b.i] = byte(r)
+		b.i++
+	}
+	if err := b.write(b._1kb[:b.i]); err != nil {
+		return &Error{Op: "WriteLatin1", Offset: start, Got: int(b.pos - start), Err: err}
+	}
+	return nil
+}
+
+// WriteASCII writes `src`, which must be strictly 7-bit ASCII. An error
+// wrapping `ErrInvalidEncoding` is returned, and nothing is written, if it is
+// not.
+func (b *Writer) WriteASCII(src string) error {
+	if b.dest == nil {
+		return b.newError("WriteASCII", ErrNilSource)
+	}
+	if i := asciiPrefixString(src); i < len(src) {
+		return b.newError("WriteASCII", fmt.Errorf("%w: byte %#02x at index %d is not ASCII", ErrInvalidEncoding, src[i], i))
+	}
+	return b.writeString("WriteASCII", src)
+}
+
+// asciiPrefix returns the length of the leading run of ASCII bytes of `p`.
+func asciiPrefix(p []byte) int {
+	for i, c := range p {
+		if c >= utf8.RuneSelf {
+			return i
+		}
+	}
+	return len(p)
+}
+
+// asciiPrefixString returns the length of the leading run of ASCII bytes of
+// `s`.
+func asciiPrefixString(s string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return i
+		}
+	}
+	return len(s)
+}